@@ -1,6 +1,7 @@
 package common
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
@@ -9,11 +10,16 @@ import (
 	"math/big"
 
 	base58 "github.com/itchyny/base58-go"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/ontio/ontology-crypto/keypair"
+	"golang.org/x/crypto/ripemd160"
 )
 
 const (
 	// ADDR_LEN address length
 	ADDR_LEN = 20
+	// MAX_MULTI_PUBKEYS max number of public keys in multi public keys address
+	MAX_MULTI_PUBKEYS = 1024
 )
 
 var (
@@ -24,6 +30,8 @@ var (
 	ErrBase58Addr = errors.New("wrong encoded address")
 	// ErrBase58Verify verify base58 address error
 	ErrBase58Verify = errors.New("base58 address verify")
+	// ErrMultiPubKeys wrong m or number of public keys for multi public keys address
+	ErrMultiPubKeys = errors.New("wrong multi public keys")
 )
 
 // Address 20 byte length array
@@ -41,6 +49,17 @@ func (addr *Address) Deserialize(r io.Reader) error {
 	return binary.Read(r, binary.LittleEndian, addr)
 }
 
+// SerializeSink implement SinkSerializable interface
+func (addr *Address) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteBytes(addr[:])
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (addr *Address) DeserializeSource(source *serialize.Source) error {
+	return source.ReadFull(addr[:])
+}
+
 // Hex get hex string corresponding to the Address
 func (addr *Address) Hex() string {
 	return fmt.Sprintf("%x", addr[:])
@@ -61,6 +80,16 @@ func (addr *Address) Base58() string {
 	return string(encoded)
 }
 
+// MarshalText implement encoding.TextMarshaler, address is encoded as Base58
+func (addr Address) MarshalText() ([]byte, error) {
+	return []byte(addr.Base58()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (addr *Address) UnmarshalText(text []byte) error {
+	return addr.FromBase58(string(text))
+}
+
 // FromBytes get Address from byte array
 func (addr *Address) FromBytes(b []byte) error {
 	if len(b) != ADDR_LEN {
@@ -97,3 +126,60 @@ func (addr *Address) FromBase58(base58addr string) error {
 
 	return nil
 }
+
+// AddressFromPubKey get Address of public key
+// with principle below:
+// address = ripemd160(sha256(public key))
+func AddressFromPubKey(pubKey keypair.PublicKey) Address {
+	return hash160(keypair.SerializePublicKey(pubKey))
+}
+
+// AddressFromMultiPubKeys get Address of m-of-n public keys
+// the public keys are sorted, thus the Address is the same with any order of keys
+// with principle below:
+// data = varuint(m) + varuint(n) + varbytes(sorted public key)...
+// address = ripemd160(sha256(data))
+func AddressFromMultiPubKeys(m int, pubKeys []keypair.PublicKey) (Address, error) {
+	n := len(pubKeys)
+	if m <= 0 || m > n || n > MAX_MULTI_PUBKEYS {
+		return ADDRESS_EMPTY, ErrMultiPubKeys
+	}
+	buf := new(bytes.Buffer)
+	var mvu = &serialize.VarUint{
+		UintType: serialize.GetUintTypeByValue(uint64(m)),
+		Value:    uint64(m),
+	}
+	if err := mvu.Serialize(buf); err != nil {
+		return ADDRESS_EMPTY, err
+	}
+	var nvu = &serialize.VarUint{
+		UintType: serialize.GetUintTypeByValue(uint64(n)),
+		Value:    uint64(n),
+	}
+	if err := nvu.Serialize(buf); err != nil {
+		return ADDRESS_EMPTY, err
+	}
+	pks := make([]keypair.PublicKey, n)
+	copy(pks, pubKeys)
+	for _, pk := range keypair.SortPublicKeys(pks) {
+		pkb := keypair.SerializePublicKey(pk)
+		var pkvb = &serialize.VarBytes{
+			Len:   uint64(len(pkb)),
+			Bytes: pkb,
+		}
+		if err := pkvb.Serialize(buf); err != nil {
+			return ADDRESS_EMPTY, err
+		}
+	}
+	return hash160(buf.Bytes()), nil
+}
+
+// hash160 ripemd160(sha256(data)), the same as smart contract address
+func hash160(data []byte) Address {
+	var addr Address
+	temp := sha256.Sum256(data)
+	md := ripemd160.New()
+	md.Write(temp[:])
+	md.Sum(addr[:0])
+	return addr
+}
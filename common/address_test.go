@@ -2,9 +2,12 @@ package common
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
 )
 
 func TestAddressSerialize(t *testing.T) {
@@ -68,3 +71,62 @@ func TestAddressBase58(t *testing.T) {
 		t.Errorf("address base58: %s", err)
 	}
 }
+
+func TestAddressFromPubKey(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key pair: %s", err)
+	}
+	addr := AddressFromPubKey(pk)
+	if addr == ADDRESS_EMPTY || addr != AddressFromPubKey(pk) {
+		t.Errorf("address from public key: %X", addr)
+	}
+}
+
+func TestAddressFromMultiPubKeys(t *testing.T) {
+	var pks []keypair.PublicKey
+	for i := 0; i < 3; i++ {
+		_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+		if err != nil {
+			t.Fatalf("generate key pair: %s", err)
+		}
+		pks = append(pks, pk)
+	}
+	addr, err := AddressFromMultiPubKeys(2, pks)
+	if err != nil {
+		t.Errorf("address from multi public keys: %s", err)
+	}
+	reversed := []keypair.PublicKey{pks[2], pks[1], pks[0]}
+	addr2, err := AddressFromMultiPubKeys(2, reversed)
+	if err != nil || addr != addr2 {
+		t.Errorf("address from multi public keys in other order:\n%X\n%X", addr, addr2)
+	}
+	if !keypair.ComparePublicKey(reversed[0], pks[2]) {
+		t.Errorf("address from multi public keys modified public keys")
+	}
+	addr3, err := AddressFromMultiPubKeys(3, pks)
+	if err != nil || addr == addr3 {
+		t.Errorf("address from multi public keys with other m: %X", addr3)
+	}
+	if _, err := AddressFromMultiPubKeys(0, pks); err != ErrMultiPubKeys {
+		t.Errorf("address from multi public keys with m = 0: %v", err)
+	}
+	if _, err := AddressFromMultiPubKeys(4, pks); err != ErrMultiPubKeys {
+		t.Errorf("address from multi public keys with m > n: %v", err)
+	}
+}
+
+func TestAddressJSON(t *testing.T) {
+	addr := Address{0xFF, 0xFE, 0xFD}
+	data, err := json.Marshal(addr)
+	if err != nil {
+		t.Fatalf("address marshal json: %s", err)
+	}
+	if string(data) != `"`+addr.Base58()+`"` {
+		t.Errorf("address marshal json: %s", data)
+	}
+	var addr2 Address
+	if err := json.Unmarshal(data, &addr2); err != nil || addr2 != addr {
+		t.Errorf("address unmarshal json: %X, %v", addr2, err)
+	}
+}
@@ -2,10 +2,20 @@ package common
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/rand"
 	"os"
+	"strconv"
+	"strings"
 )
 
+// ErrUnknownName name is not one of the enum names
+var ErrUnknownName = errors.New("unknown name")
+
+// HexBytes byte array encoded as hex string in text, i.e. JSON
+type HexBytes []byte
+
 // Nonce returns random nonce
 func Nonce() uint64 {
 	// TODO: replace with the real random number generator
@@ -23,6 +33,46 @@ func HexToBytes(value string) ([]byte, error) {
 	return hex.DecodeString(value)
 }
 
+// MarshalText implement encoding.TextMarshaler
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(Hex(b)), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	data, err := HexToBytes(string(text))
+	if err != nil {
+		return err
+	}
+	*b = data
+	return nil
+}
+
+// ByteName get the name of byte enum value in names,
+// the value without name is formatted as hex, i.e. 0x0f
+func ByteName(v byte, names map[byte]string) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", v)
+}
+
+// ParseByteName get the byte enum value by name, see ByteName as reference
+func ParseByteName(name string, names map[byte]string) (byte, error) {
+	for v, n := range names {
+		if n == name {
+			return v, nil
+		}
+	}
+	if strings.HasPrefix(name, "0x") {
+		v, err := strconv.ParseUint(name[2:], 16, 8)
+		if err == nil {
+			return byte(v), nil
+		}
+	}
+	return 0, ErrUnknownName
+}
+
 // FileExisted checks whether filename exists in filesystem
 func FileExisted(filename string) bool {
 	_, err := os.Stat(filename)
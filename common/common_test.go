@@ -41,3 +41,19 @@ func TestFileExist(t *testing.T) {
 		t.Errorf("common fileexits")
 	}
 }
+
+func TestByteName(t *testing.T) {
+	names := map[byte]string{0x01: "One"}
+	if ByteName(0x01, names) != "One" || ByteName(0x0f, names) != "0x0f" {
+		t.Errorf("byte name: %s, %s", ByteName(0x01, names), ByteName(0x0f, names))
+	}
+	for _, name := range []string{"One", "0x0f"} {
+		v, err := ParseByteName(name, names)
+		if err != nil || ByteName(v, names) != name {
+			t.Errorf("parse byte name %s: %X, %v", name, v, err)
+		}
+	}
+	if _, err := ParseByteName("Two", names); err != ErrUnknownName {
+		t.Errorf("parse unknown name: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package serialize_test
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/common/serialize/serializetest"
+)
+
+func FuzzVarUint(f *testing.F) {
+	for _, v := range []uint64{0, 0xFC, 0xFD, 0xFFFF, 0x10000, 0xFFFFFFFF, 0x100000000} {
+		serializetest.AddSeeds(f, &serialize.VarUint{UintType: serialize.GetUintTypeByValue(v), Value: v})
+	}
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(serialize.VarUint) })
+}
+
+func FuzzVarBytes(f *testing.F) {
+	serializetest.AddSeeds(f,
+		&serialize.VarBytes{},
+		&serialize.VarBytes{Len: 4, Bytes: []byte("test")},
+	)
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(serialize.VarBytes) })
+}
@@ -0,0 +1,48 @@
+package serialize
+
+import (
+	"bytes"
+	"errors"
+)
+
+const (
+	// MaxVarBytesLen default max length of VarBytes
+	MaxVarBytesLen = 16 * 1024 * 1024
+	// MaxMessageSize max size of data decoded by Decode
+	MaxMessageSize = 32 * 1024 * 1024
+	// readChunkSize bytes arrays longer than it are read incrementally
+	readChunkSize = 64 * 1024
+)
+
+var (
+	// ErrExceedLimit length or count of field exceeds the limit
+	ErrExceedLimit = errors.New("exceed the limit")
+	// ErrTrailingBytes data remains after deserialization
+	ErrTrailingBytes = errors.New("trailing bytes after deserialization")
+)
+
+// Decode deserialize s from data of untrusted source, i.e. network message,
+// data must not be larger than MaxMessageSize, and must be consumed completely
+func Decode(data []byte, s Serializable) error {
+	if len(data) > MaxMessageSize {
+		return ErrExceedLimit
+	}
+	if ss, ok := s.(SinkSerializable); ok {
+		source := NewSource(data)
+		if err := ss.DeserializeSource(source); err != nil {
+			return err
+		}
+		if source.Len() != 0 {
+			return ErrTrailingBytes
+		}
+		return nil
+	}
+	r := bytes.NewReader(data)
+	if err := s.Deserialize(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return ErrTrailingBytes
+	}
+	return nil
+}
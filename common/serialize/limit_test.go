@@ -0,0 +1,18 @@
+package serialize
+
+import (
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	var vb VarBytes
+	if err := Decode([]byte{0x04, 't', 'e', 's', 't'}, &vb); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+	if string(vb.Bytes) != "test" {
+		t.Errorf("decode: got %q", vb.Bytes)
+	}
+	if err := Decode([]byte{0x04, 't', 'e', 's', 't', 0x00}, &vb); err != ErrTrailingBytes {
+		t.Errorf("expect ErrTrailingBytes, got %v", err)
+	}
+}
@@ -1,6 +1,12 @@
 package serialize
 
-import "io"
+import (
+	"errors"
+	"io"
+)
+
+// ErrInvalidBool bool is encoded as neither 0 nor 1
+var ErrInvalidBool = errors.New("invalid bool")
 
 // Serializable serialize to or deserialize from byte array
 type Serializable interface {
@@ -0,0 +1,46 @@
+// Package serializetest fuzz helpers for the hand-written serialize.Serializable codecs
+package serializetest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mileschao/echain/common/serialize"
+)
+
+// NewFunc create an new empty value to be deserialized into
+type NewFunc func() serialize.Serializable
+
+// AddSeeds add the serialized values to the seed corpus of f
+func AddSeeds(f *testing.F, values ...serialize.Serializable) {
+	for _, v := range values {
+		buf := new(bytes.Buffer)
+		if err := v.Serialize(buf); err != nil {
+			f.Fatalf("serialize seed: %s", err)
+		}
+		f.Add(buf.Bytes())
+	}
+}
+
+// Fuzz run the round-trip check on the inputs of f
+func Fuzz(f *testing.F, newValue NewFunc) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		RoundTrip(t, data, newValue)
+	})
+}
+
+// RoundTrip deserialize data with serialize.Decode, which must not panic,
+// and the accepted data must be serialized back to the identical bytes
+func RoundTrip(t *testing.T, data []byte, newValue NewFunc) {
+	v := newValue()
+	if err := serialize.Decode(data, v); err != nil {
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err := v.Serialize(buf); err != nil {
+		t.Fatalf("serialize accepted data: %s\n%X", err, data)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("round trip mismatch:\n%X\n%X", data, buf.Bytes())
+	}
+}
@@ -0,0 +1,152 @@
+package serialize
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+)
+
+// Sink serialize values into an growing byte slice without reflection,
+// the encoding is the same as Serialize methods of VarUint and VarBytes
+type Sink struct {
+	buf []byte
+}
+
+// NewSink create an new sink appending to buf, which may be nil
+func NewSink(buf []byte) *Sink {
+	return &Sink{buf: buf[:0]}
+}
+
+var sinkPool = sync.Pool{
+	New: func() interface{} {
+		return NewSink(make([]byte, 0, 512))
+	},
+}
+
+// AcquireSink get an empty sink from pool, which should be released by ReleaseSink
+// after its bytes are no longer used
+func AcquireSink() *Sink {
+	return sinkPool.Get().(*Sink)
+}
+
+// ReleaseSink reset the sink and put it back to pool
+func ReleaseSink(sink *Sink) {
+	sink.Reset()
+	sinkPool.Put(sink)
+}
+
+// Bytes get the serialized bytes, which are valid until the next write or Reset
+func (sink *Sink) Bytes() []byte {
+	return sink.buf
+}
+
+// Len get the length of serialized bytes
+func (sink *Sink) Len() int {
+	return len(sink.buf)
+}
+
+// Reset clear the serialized bytes and keep the buffer for reuse
+func (sink *Sink) Reset() {
+	sink.buf = sink.buf[:0]
+}
+
+// Write implement io.Writer interface, the bytes are appended to sink
+func (sink *Sink) Write(p []byte) (int, error) {
+	sink.buf = append(sink.buf, p...)
+	return len(p), nil
+}
+
+// WriteUint8 write an uint8
+func (sink *Sink) WriteUint8(v uint8) {
+	sink.buf = append(sink.buf, v)
+}
+
+// WriteBool write an bool as 1 or 0
+func (sink *Sink) WriteBool(v bool) {
+	if v {
+		sink.WriteUint8(1)
+	} else {
+		sink.WriteUint8(0)
+	}
+}
+
+// WriteUint16 write an uint16 in little endian
+func (sink *Sink) WriteUint16(v uint16) {
+	sink.buf = append(sink.buf, byte(v), byte(v>>8))
+}
+
+// WriteUint32 write an uint32 in little endian
+func (sink *Sink) WriteUint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	sink.buf = append(sink.buf, b[:]...)
+}
+
+// WriteUint64 write an uint64 in little endian
+func (sink *Sink) WriteUint64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	sink.buf = append(sink.buf, b[:]...)
+}
+
+// WriteBytes write the bytes as they are, without length
+func (sink *Sink) WriteBytes(b []byte) {
+	sink.buf = append(sink.buf, b...)
+}
+
+// WriteVarUint write an variable unsigned integer in the shortest form
+func (sink *Sink) WriteVarUint(v uint64) {
+	switch {
+	case v < VarUint16:
+		sink.WriteUint8(uint8(v))
+	case v <= math.MaxUint16:
+		sink.WriteUint8(VarUint16)
+		sink.WriteUint16(uint16(v))
+	case v <= math.MaxUint32:
+		sink.WriteUint8(VarUint32)
+		sink.WriteUint32(uint32(v))
+	default:
+		sink.WriteUint8(VarUint64)
+		sink.WriteUint64(v)
+	}
+}
+
+// WriteVarBytes write the bytes with variable length
+func (sink *Sink) WriteVarBytes(b []byte) {
+	sink.WriteVarUint(uint64(len(b)))
+	sink.WriteBytes(b)
+}
+
+// WriteString write the string with variable length
+func (sink *Sink) WriteString(s string) {
+	sink.WriteVarUint(uint64(len(s)))
+	sink.buf = append(sink.buf, s...)
+}
+
+// SinkSerializable serialize into Sink and deserialize from Source
+type SinkSerializable interface {
+	SerializeSink(sink *Sink) error
+	DeserializeSource(source *Source) error
+}
+
+// WriteTo serialize s into w through an pooled sink,
+// it is used to implement Serializable by SinkSerializable
+func WriteTo(w io.Writer, s SinkSerializable) error {
+	sink := AcquireSink()
+	defer ReleaseSink(sink)
+	if err := s.SerializeSink(sink); err != nil {
+		return err
+	}
+	_, err := w.Write(sink.Bytes())
+	return err
+}
+
+// ToBytes serialize s into an new byte slice
+func ToBytes(s SinkSerializable) ([]byte, error) {
+	sink := NewSink(nil)
+	if err := s.SerializeSink(sink); err != nil {
+		return nil, err
+	}
+	return sink.Bytes(), nil
+}
@@ -0,0 +1,207 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestSinkSource(t *testing.T) {
+	sink := NewSink(nil)
+	sink.WriteUint8(0xF0)
+	sink.WriteBool(true)
+	sink.WriteUint16(0xF0FE)
+	sink.WriteUint32(0xFFF0FFFE)
+	sink.WriteUint64(0xFFF0FFF1FFF2FFFE)
+	sink.WriteBytes([]byte{0x01, 0x02})
+	sink.WriteVarUint(0xFD)
+	sink.WriteVarBytes([]byte("test"))
+	sink.WriteString("echain")
+
+	source := NewSource(sink.Bytes())
+	if v, err := source.ReadUint8(); err != nil || v != 0xF0 {
+		t.Errorf("read uint8: %X, %v", v, err)
+	}
+	if v, err := source.ReadBool(); err != nil || !v {
+		t.Errorf("read bool: %v, %v", v, err)
+	}
+	if v, err := source.ReadUint16(); err != nil || v != 0xF0FE {
+		t.Errorf("read uint16: %X, %v", v, err)
+	}
+	if v, err := source.ReadUint32(); err != nil || v != 0xFFF0FFFE {
+		t.Errorf("read uint32: %X, %v", v, err)
+	}
+	if v, err := source.ReadUint64(); err != nil || v != 0xFFF0FFF1FFF2FFFE {
+		t.Errorf("read uint64: %X, %v", v, err)
+	}
+	var fixed [2]byte
+	if err := source.ReadFull(fixed[:]); err != nil || fixed != [2]byte{0x01, 0x02} {
+		t.Errorf("read full: %X, %v", fixed, err)
+	}
+	if v, err := source.ReadVarUint(0xFD); err != nil || v != 0xFD {
+		t.Errorf("read varuint: %X, %v", v, err)
+	}
+	if v, err := source.ReadVarBytes(MaxVarBytesLen); err != nil || string(v) != "test" {
+		t.Errorf("read varbytes: %q, %v", v, err)
+	}
+	if v, err := source.ReadString(MaxVarBytesLen); err != nil || v != "echain" {
+		t.Errorf("read string: %q, %v", v, err)
+	}
+	if source.Len() != 0 || source.Pos() != sink.Len() {
+		t.Errorf("source remains %d bytes at %d", source.Len(), source.Pos())
+	}
+	if _, err := source.ReadUint8(); err != io.ErrUnexpectedEOF {
+		t.Errorf("read at end: %v", err)
+	}
+}
+
+func TestSinkCompatible(t *testing.T) {
+	for _, v := range []uint64{0, 0xFC, 0xFD, 0xFFFF, 0x10000, 0xFFFFFFFF, 0x100000000} {
+		buf := new(bytes.Buffer)
+		vb := VarBytes{Len: v % 0x20000, Bytes: make([]byte, v%0x20000)}
+		vu := VarUint{UintType: GetUintTypeByValue(v), Value: v}
+		if err := vu.Serialize(buf); err != nil {
+			t.Fatalf("serialize varuint %X: %s", v, err)
+		}
+		if err := vb.Serialize(buf); err != nil {
+			t.Fatalf("serialize varbytes %X: %s", vb.Len, err)
+		}
+		sink := NewSink(nil)
+		sink.WriteVarUint(v)
+		sink.WriteVarBytes(vb.Bytes)
+		if !bytes.Equal(sink.Bytes(), buf.Bytes()) {
+			t.Errorf("sink of %X differs from VarUint and VarBytes", v)
+		}
+	}
+}
+
+func TestSourceInvalid(t *testing.T) {
+	if _, err := NewSource([]byte{0xFD, 0x01, 0x00}).ReadVarUint(math.MaxUint16); err != ErrNonCanonical {
+		t.Errorf("expect ErrNonCanonical, got %v", err)
+	}
+	if _, err := NewSource([]byte{0x11}).ReadVarUint(0x10); err != ErrExceedLimit {
+		t.Errorf("expect ErrExceedLimit, got %v", err)
+	}
+	if _, err := NewSource([]byte{0x02}).ReadBool(); err != ErrInvalidBool {
+		t.Errorf("expect ErrInvalidBool, got %v", err)
+	}
+	data := []byte{0xFE, 0x00, 0x00, 0x00, 0x01, 't', 'e', 's', 't'}
+	if _, err := NewSource(data).ReadVarBytes(MaxVarBytesLen); err != io.ErrUnexpectedEOF {
+		t.Errorf("expect io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	data := []byte{0x04, 't', 'e', 's', 't', 0xFF}
+	var vb sinkVarBytes
+	buf := bytes.NewBuffer(data)
+	if err := ReadFrom(buf, &vb); err != nil || string(vb) != "test" || buf.Len() != 1 {
+		t.Errorf("read from buffer: %q, %v, remains %d", vb, err, buf.Len())
+	}
+	r := bytes.NewReader(data)
+	if err := ReadFrom(r, &vb); err != nil || string(vb) != "test" || r.Len() != 1 {
+		t.Errorf("read from reader: %q, %v, remains %d", vb, err, r.Len())
+	}
+	w := new(bytes.Buffer)
+	if err := WriteTo(w, &vb); err != nil || !bytes.Equal(w.Bytes(), data[:5]) {
+		t.Errorf("write to: %X, %v", w.Bytes(), err)
+	}
+}
+
+func TestReadFromStream(t *testing.T) {
+	data := []byte{0x04, 't', 'e', 's', 't', 0x02, 'o', 'k'}
+	// non-seekable reader split in the middle of value
+	r := io.MultiReader(bytes.NewReader(data[:3]), bytes.NewReader(data[3:]))
+	var vb sinkVarBytes
+	for _, expect := range []string{"test", "ok"} {
+		if err := ReadFrom(r, &vb); err != nil || string(vb) != expect {
+			t.Errorf("read from stream: %q, %v, expect %q", vb, err, expect)
+		}
+	}
+	if err := ReadFrom(r, &vb); err != io.EOF {
+		t.Errorf("read from end of stream, expect io.EOF, got %v", err)
+	}
+	r = io.MultiReader(bytes.NewReader(data[:3]))
+	if err := ReadFrom(r, &vb); err != io.ErrUnexpectedEOF {
+		t.Errorf("read from truncated stream, expect io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+type sinkVarBytes []byte
+
+func (vb *sinkVarBytes) SerializeSink(sink *Sink) error {
+	sink.WriteVarBytes(*vb)
+	return nil
+}
+
+func (vb *sinkVarBytes) DeserializeSource(source *Source) error {
+	b, err := source.ReadVarBytes(MaxVarBytesLen)
+	*vb = b
+	return err
+}
+
+func BenchmarkWriteReflect(b *testing.B) {
+	data := make([]byte, 64)
+	buf := new(bytes.Buffer)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		binary.Write(buf, binary.LittleEndian, uint32(i))
+		binary.Write(buf, binary.LittleEndian, uint64(i))
+		vu := VarUint{UintType: GetUintTypeByValue(uint64(i % 0x100)), Value: uint64(i % 0x100)}
+		vu.Serialize(buf)
+		vb := VarBytes{Len: uint64(len(data)), Bytes: data}
+		vb.Serialize(buf)
+	}
+}
+
+func BenchmarkWriteSink(b *testing.B) {
+	data := make([]byte, 64)
+	sink := NewSink(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink.Reset()
+		sink.WriteUint32(uint32(i))
+		sink.WriteUint64(uint64(i))
+		sink.WriteVarUint(uint64(i % 0x100))
+		sink.WriteVarBytes(data)
+	}
+}
+
+func BenchmarkReadReflect(b *testing.B) {
+	sink := NewSink(nil)
+	sink.WriteUint32(1)
+	sink.WriteUint64(2)
+	sink.WriteVarUint(0xFD)
+	sink.WriteVarBytes(make([]byte, 64))
+	data := sink.Bytes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := bytes.NewReader(data)
+		var v32 uint32
+		var v64 uint64
+		binary.Read(r, binary.LittleEndian, &v32)
+		binary.Read(r, binary.LittleEndian, &v64)
+		ReadVarUint(r, math.MaxUint16)
+		ReadVarBytes(r, MaxVarBytesLen)
+	}
+}
+
+func BenchmarkReadSource(b *testing.B) {
+	sink := NewSink(nil)
+	sink.WriteUint32(1)
+	sink.WriteUint64(2)
+	sink.WriteVarUint(0xFD)
+	sink.WriteVarBytes(make([]byte, 64))
+	data := sink.Bytes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		source := NewSource(data)
+		source.ReadUint32()
+		source.ReadUint64()
+		source.ReadVarUint(math.MaxUint16)
+		source.ReadVarBytes(MaxVarBytesLen)
+	}
+}
@@ -0,0 +1,223 @@
+package serialize
+
+import (
+	"bytes"
+	"io"
+	"math"
+)
+
+// Source deserialize values from byte slice or io.Reader without reflection,
+// io.ErrUnexpectedEOF returned if the remains are not enough for the value
+type Source struct {
+	data    []byte
+	off     int
+	r       io.Reader
+	scratch [8]byte
+}
+
+// NewSource create an new source reading from data
+func NewSource(data []byte) *Source {
+	return &Source{data: data}
+}
+
+// NewReaderSource create an new source reading from r incrementally,
+// r is never read past the bytes of the values deserialized
+func NewReaderSource(r io.Reader) *Source {
+	return &Source{r: r}
+}
+
+// Len get the length of unread bytes, it is always 0 for source of io.Reader
+func (source *Source) Len() int {
+	return len(source.data) - source.off
+}
+
+// Pos get the number of bytes read
+func (source *Source) Pos() int {
+	return source.off
+}
+
+// next get the next n bytes without copy
+func (source *Source) next(n uint64) ([]byte, error) {
+	if source.r != nil {
+		return source.read(n)
+	}
+	if uint64(source.Len()) < n {
+		source.off = len(source.data)
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := source.data[source.off : source.off+int(n)]
+	source.off += int(n)
+	return b, nil
+}
+
+// read read the next n bytes from io.Reader, the bytes are valid until next read.
+// io.EOF returned if r ends before any byte is read from source
+func (source *Source) read(n uint64) ([]byte, error) {
+	var b []byte
+	var err error
+	if n <= uint64(len(source.scratch)) {
+		b = source.scratch[:n]
+		_, err = io.ReadFull(source.r, b)
+	} else {
+		b, err = readBytes(source.r, n)
+	}
+	if err == io.EOF && source.off != 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	source.off += int(n)
+	return b, nil
+}
+
+// Read implement io.Reader interface, i.e. deserialize the values
+// which only implement Serializable from source
+func (source *Source) Read(p []byte) (int, error) {
+	if source.r != nil {
+		n, err := source.r.Read(p)
+		source.off += n
+		return n, err
+	}
+	if len(p) > 0 && source.Len() == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, source.data[source.off:])
+	source.off += n
+	return n, nil
+}
+
+// ReadUint8 read an uint8
+func (source *Source) ReadUint8() (uint8, error) {
+	b, err := source.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// ReadBool read an bool encoded in one byte, which must be 0 or 1
+func (source *Source) ReadBool() (bool, error) {
+	b, err := source.ReadUint8()
+	if err != nil {
+		return false, err
+	}
+	if b > 1 {
+		return false, ErrInvalidBool
+	}
+	return b == 1, nil
+}
+
+// ReadUint16 read an uint16 in little endian
+func (source *Source) ReadUint16() (uint16, error) {
+	b, err := source.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(b[0]) | uint16(b[1])<<8, nil
+}
+
+// ReadUint32 read an uint32 in little endian
+func (source *Source) ReadUint32() (uint32, error) {
+	b, err := source.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, nil
+}
+
+// ReadUint64 read an uint64 in little endian
+func (source *Source) ReadUint64() (uint64, error) {
+	b, err := source.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+		uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56, nil
+}
+
+// ReadFull read exactly len(p) bytes into p, i.e. fixed size array
+func (source *Source) ReadFull(p []byte) error {
+	b, err := source.next(uint64(len(p)))
+	if err != nil {
+		return err
+	}
+	copy(p, b)
+	return nil
+}
+
+// ReadVarUint read an variable unsigned integer not more than max
+// ErrNonCanonical returned if the value could be encoded in a shorter form
+func (source *Source) ReadVarUint(max uint64) (uint64, error) {
+	t, err := source.ReadUint8()
+	if err != nil {
+		return 0, err
+	}
+	var v, min uint64
+	switch t {
+	case VarUint16:
+		v16, err := source.ReadUint16()
+		if err != nil {
+			return 0, err
+		}
+		v, min = uint64(v16), VarUint16
+	case VarUint32:
+		v32, err := source.ReadUint32()
+		if err != nil {
+			return 0, err
+		}
+		v, min = uint64(v32), math.MaxUint16+1
+	case VarUint64:
+		if v, err = source.ReadUint64(); err != nil {
+			return 0, err
+		}
+		min = math.MaxUint32 + 1
+	default:
+		v = uint64(t)
+	}
+	if v < min {
+		return 0, ErrNonCanonical
+	}
+	if v > max {
+		return 0, ErrExceedLimit
+	}
+	return v, nil
+}
+
+// ReadVarBytes read an copy of variable bytes array not longer than max
+func (source *Source) ReadVarBytes(max uint64) ([]byte, error) {
+	n, err := source.ReadVarUint(max)
+	if err != nil {
+		return nil, err
+	}
+	b, err := source.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, b...), nil
+}
+
+// ReadString read an variable length string not longer than max
+func (source *Source) ReadString(max uint64) (string, error) {
+	n, err := source.ReadVarUint(max)
+	if err != nil {
+		return "", err
+	}
+	b, err := source.next(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ReadFrom deserialize s from r through Source, it is used to implement Serializable
+// by SinkSerializable. only the bytes of s are consumed from r
+func ReadFrom(r io.Reader, s SinkSerializable) error {
+	if buf, ok := r.(*bytes.Buffer); ok {
+		source := NewSource(buf.Bytes())
+		err := s.DeserializeSource(source)
+		buf.Next(source.Pos())
+		return err
+	}
+	return s.DeserializeSource(NewReaderSource(r))
+}
@@ -1,6 +1,7 @@
 package serialize
 
 import (
+	"bytes"
 	"encoding/binary"
 	"io"
 )
@@ -27,12 +28,42 @@ func (vb *VarBytes) Serialize(w io.Writer) error {
 // Deserialize implement Deserialiazable interface
 // deserialize a variable bytes arrary from buffer
 // see Serialize above as reference
+// the length must not be more than MaxVarBytesLen
 func (vb *VarBytes) Deserialize(r io.Reader) error {
-	var varlen VarUint
-	if err := varlen.Deserialize(r); err != nil {
+	data, err := ReadVarBytes(r, MaxVarBytesLen)
+	if err != nil {
 		return err
 	}
-	vb.Len = uint64(varlen.Value)
-	vb.Bytes = make([]byte, vb.Len)
-	return binary.Read(r, binary.LittleEndian, vb.Bytes)
+	vb.Len = uint64(len(data))
+	vb.Bytes = data
+	return nil
+}
+
+// ReadVarBytes read an variable bytes array not longer than max
+func ReadVarBytes(r io.Reader, max uint64) ([]byte, error) {
+	n, err := ReadVarUint(r, max)
+	if err != nil {
+		return nil, err
+	}
+	return readBytes(r, n)
+}
+
+// readBytes read n bytes, the buffer grows with the data read
+// rather than allocating n bytes of untrusted length at once
+func readBytes(r io.Reader, n uint64) ([]byte, error) {
+	if n <= readChunkSize {
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	buf := new(bytes.Buffer)
+	if _, err := io.CopyN(buf, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
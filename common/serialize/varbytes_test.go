@@ -51,3 +51,22 @@ func TestVarBytesDeserialize(t *testing.T) {
 	}
 
 }
+
+func TestReadVarBytesLimit(t *testing.T) {
+	data := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	if _, err := ReadVarBytes(bytes.NewReader(data), MaxVarBytesLen); err != ErrExceedLimit {
+		t.Errorf("expect ErrExceedLimit, got %v", err)
+	}
+	var vb VarBytes
+	if err := vb.Deserialize(bytes.NewReader(data)); err != ErrExceedLimit {
+		t.Errorf("expect ErrExceedLimit, got %v", err)
+	}
+}
+
+func TestReadVarBytesTruncated(t *testing.T) {
+	// length within limit but much longer than the data actually sent
+	data := []byte{0xFE, 0x00, 0x00, 0x00, 0x01, 't', 'e', 's', 't'}
+	if _, err := ReadVarBytes(bytes.NewReader(data), MaxVarBytesLen); err == nil {
+		t.Errorf("expect error on truncated bytes")
+	}
+}
@@ -10,6 +10,8 @@ import (
 var (
 	// ErrWrongUintType VarUint with wrong UintType for value
 	ErrWrongUintType = errors.New("error var uint type with value")
+	// ErrNonCanonical VarUint is not encoded in the shortest form
+	ErrNonCanonical = errors.New("non-canonical var uint")
 )
 
 const (
@@ -50,43 +52,71 @@ func GetUintTypeByValue(v uint64) uint8 {
 // uint64: 0xFF + uint64(LE)
 func (vu *VarUint) Serialize(w io.Writer) error {
 	if vu.UintType < VarUint16 {
-		binary.Write(w, binary.LittleEndian, uint8(vu.Value))
-	} else {
-		if vu.UintType == VarUint16 && vu.Value > VarUint16 && vu.Value <= math.MaxUint16 {
-			binary.Write(w, binary.LittleEndian, vu.UintType)
-			binary.Write(w, binary.LittleEndian, uint16(vu.Value))
-		} else if vu.UintType == VarUint32 && vu.Value > math.MaxUint16 && vu.Value <= math.MaxUint32 {
-			binary.Write(w, binary.LittleEndian, vu.UintType)
-			binary.Write(w, binary.LittleEndian, uint32(vu.Value))
-		} else if vu.UintType == VarUint64 && vu.Value > math.MaxUint32 && vu.Value <= math.MaxUint64 {
-			binary.Write(w, binary.LittleEndian, vu.UintType)
-			binary.Write(w, binary.LittleEndian, vu.Value)
-		} else {
+		if vu.Value != uint64(vu.UintType) {
 			return ErrWrongUintType
 		}
+		return binary.Write(w, binary.LittleEndian, uint8(vu.Value))
 	}
-	return nil
+	var v interface{}
+	if vu.UintType == VarUint16 && vu.Value >= VarUint16 && vu.Value <= math.MaxUint16 {
+		v = uint16(vu.Value)
+	} else if vu.UintType == VarUint32 && vu.Value > math.MaxUint16 && vu.Value <= math.MaxUint32 {
+		v = uint32(vu.Value)
+	} else if vu.UintType == VarUint64 && vu.Value > math.MaxUint32 {
+		v = vu.Value
+	} else {
+		return ErrWrongUintType
+	}
+	if err := binary.Write(w, binary.LittleEndian, vu.UintType); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, v)
 }
 
 // Deserialize implement Serializable interface
 // deserialize variable unsigned integer
 // see method Serialize as reference
+// ErrNonCanonical returned if the value could be encoded in a shorter form
 func (vu *VarUint) Deserialize(r io.Reader) error {
-	binary.Read(r, binary.LittleEndian, &vu.UintType)
-	if vu.UintType == VarUint16 {
+	if err := binary.Read(r, binary.LittleEndian, &vu.UintType); err != nil {
+		return err
+	}
+	var min uint64
+	switch vu.UintType {
+	case VarUint16:
 		var v uint16
-		binary.Read(r, binary.LittleEndian, &v)
-		vu.Value = uint64(v)
-	} else if vu.UintType == VarUint32 {
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return err
+		}
+		vu.Value, min = uint64(v), VarUint16
+	case VarUint32:
 		var v uint32
-		binary.Read(r, binary.LittleEndian, &v)
-		vu.Value = uint64(v)
-	} else if vu.UintType == VarUint64 {
-		var v uint64
-		binary.Read(r, binary.LittleEndian, &v)
-		vu.Value = uint64(v)
-	} else {
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return err
+		}
+		vu.Value, min = uint64(v), math.MaxUint16+1
+	case VarUint64:
+		if err := binary.Read(r, binary.LittleEndian, &vu.Value); err != nil {
+			return err
+		}
+		min = math.MaxUint32 + 1
+	default:
 		vu.Value = uint64(vu.UintType)
 	}
+	if vu.Value < min {
+		return ErrNonCanonical
+	}
 	return nil
 }
+
+// ReadVarUint read an variable unsigned integer not more than max
+func ReadVarUint(r io.Reader, max uint64) (uint64, error) {
+	var vu VarUint
+	if err := vu.Deserialize(r); err != nil {
+		return 0, err
+	}
+	if vu.Value > max {
+		return 0, ErrExceedLimit
+	}
+	return vu.Value, nil
+}
@@ -130,3 +130,42 @@ func TestVarUintDerialize(t *testing.T) {
 	}
 
 }
+
+func TestVarUintBoundary(t *testing.T) {
+	for _, v := range []uint64{0xFC, 0xFD, 0xFFFF, 0x10000, 0xFFFFFFFF, 0x100000000} {
+		b := new(bytes.Buffer)
+		vu := VarUint{UintType: GetUintTypeByValue(v), Value: v}
+		if err := vu.Serialize(b); err != nil {
+			t.Fatalf("serialize %X: %s", v, err)
+		}
+		var dvu VarUint
+		if err := dvu.Deserialize(b); err != nil {
+			t.Fatalf("deserialize %X: %s", v, err)
+		}
+		if dvu.Value != v {
+			t.Errorf("deserialize %X: got %X", v, dvu.Value)
+		}
+	}
+}
+
+func TestVarUintNonCanonical(t *testing.T) {
+	for _, data := range [][]byte{
+		{0xFD, 0x01, 0x00},
+		{0xFE, 0xFF, 0xFF, 0x00, 0x00},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00},
+	} {
+		var vu VarUint
+		if err := vu.Deserialize(bytes.NewReader(data)); err != ErrNonCanonical {
+			t.Errorf("deserialize %X: expect ErrNonCanonical, got %v", data, err)
+		}
+	}
+}
+
+func TestReadVarUintLimit(t *testing.T) {
+	if _, err := ReadVarUint(bytes.NewReader([]byte{0x11}), 0x10); err != ErrExceedLimit {
+		t.Errorf("expect ErrExceedLimit, got %v", err)
+	}
+	if v, err := ReadVarUint(bytes.NewReader([]byte{0x10}), 0x10); err != nil || v != 0x10 {
+		t.Errorf("read varuint: %X, %v", v, err)
+	}
+}
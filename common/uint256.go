@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+
+	"github.com/mileschao/echain/common/serialize"
 )
 
 /*
@@ -39,6 +41,31 @@ func (u *Uint256) Deserialize(r io.Reader) error {
 	return binary.Read(r, binary.LittleEndian, u)
 }
 
+// SerializeSink implement SinkSerializable interface
+func (u *Uint256) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteBytes(u[:])
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (u *Uint256) DeserializeSource(source *serialize.Source) error {
+	return source.ReadFull(u[:])
+}
+
+// MarshalText implement encoding.TextMarshaler, uint256 is encoded as hex string
+func (u Uint256) MarshalText() ([]byte, error) {
+	return []byte(Hex(u[:])), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (u *Uint256) UnmarshalText(text []byte) error {
+	b, err := HexToBytes(string(text))
+	if err != nil {
+		return err
+	}
+	return u.FromBytes(b)
+}
+
 // Bytes return bytes with copied content
 func (u *Uint256) Bytes() []byte {
 	b := make([]byte, UINT256_SIZE)
@@ -3,6 +3,7 @@ package common
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -65,3 +66,21 @@ func TestUint256FromBytes(t *testing.T) {
 		t.Errorf("uint256 frombytes: \nuint256-%X\nbuffer -%X", u256, bs)
 	}
 }
+
+func TestUint256Text(t *testing.T) {
+	u256 := Uint256{0xFE, 0xFF, 0xFD}
+	text, err := u256.MarshalText()
+	if err != nil {
+		t.Fatalf("uint256 marshal text: %s", err)
+	}
+	if string(text) != "fefffd"+strings.Repeat("00", UINT256_SIZE-3) {
+		t.Errorf("uint256 marshal text: %s", text)
+	}
+	var u2 Uint256
+	if err := u2.UnmarshalText(text); err != nil || u2 != u256 {
+		t.Errorf("uint256 unmarshal text: %X, %v", u2, err)
+	}
+	if err := u2.UnmarshalText([]byte("fefffd")); err != ErrBytesSize {
+		t.Errorf("uint256 unmarshal short text: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package block
+
+import (
+	"errors"
+	"io"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/merkletree"
+)
+
+const (
+	// MaxBlockTransactions max number of transactions in block
+	MaxBlockTransactions = 65536
+)
+
+var (
+	// ErrNilHeader block without header
+	ErrNilHeader = errors.New("block header is nil")
+	// ErrTransactionsRoot transactions root in header do not match block body
+	ErrTransactionsRoot = errors.New("transactions root mismatch")
+	// ErrDuplicateTransaction same transaction appears more than once in block
+	ErrDuplicateTransaction = errors.New("duplicate transaction in block")
+)
+
+// Block block header with transactions body
+type Block struct {
+	Header       *Header                    `json:"header"`
+	Transactions []*transaction.Transaction `json:"transactions"`
+}
+
+// NewBlock create an new block with header and transactions
+// the TransactionsRoot of header is calculated by the transactions' hashes
+func NewBlock(header *Header, txs []*transaction.Transaction) *Block {
+	header.TransactionsRoot = TransactionsRoot(txs)
+	header.hash = nil
+	return &Block{
+		Header:       header,
+		Transactions: txs,
+	}
+}
+
+// TransactionsRoot calculate merkle tree root of transaction hashes
+func TransactionsRoot(txs []*transaction.Transaction) common.Uint256 {
+	hashes := make([]common.Uint256, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash())
+	}
+	return merkletree.CalcMerkleTreeRoot(hashes)
+}
+
+// Serialize implement Serializable interface
+func (b *Block) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, b)
+}
+
+// Deserialize implement Serializable interface
+func (b *Block) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, b)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (b *Block) SerializeSink(sink *serialize.Sink) error {
+	if b.Header == nil {
+		return ErrNilHeader
+	}
+	if err := b.Header.SerializeSink(sink); err != nil {
+		return err
+	}
+	sink.WriteVarUint(uint64(len(b.Transactions)))
+	for _, tx := range b.Transactions {
+		if err := tx.SerializeSink(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (b *Block) DeserializeSource(source *serialize.Source) error {
+	b.Header = new(Header)
+	if err := b.Header.DeserializeSource(source); err != nil {
+		return err
+	}
+	n, err := source.ReadVarUint(MaxBlockTransactions)
+	if err != nil {
+		return err
+	}
+	b.Transactions = make([]*transaction.Transaction, 0)
+	for i := uint64(0); i < n; i++ {
+		tx := new(transaction.Transaction)
+		if err := tx.DeserializeSource(source); err != nil {
+			return err
+		}
+		b.Transactions = append(b.Transactions, tx)
+	}
+	return nil
+}
+
+// Hash get the hash value of block, which is the hash of block header
+func (b *Block) Hash() common.Uint256 {
+	return b.Header.Hash()
+}
+
+// Verify check whether the transactions of block match the TransactionsRoot of header.
+// duplicate transactions are rejected, since the merkle root duplicating the odd last
+// node can not tell [a,b,c,c] from [a,b,c] (CVE-2012-2459)
+func (b *Block) Verify() error {
+	if b.Header == nil {
+		return ErrNilHeader
+	}
+	hashes := make([]common.Uint256, 0, len(b.Transactions))
+	seen := make(map[common.Uint256]struct{}, len(b.Transactions))
+	for _, tx := range b.Transactions {
+		hash := tx.Hash()
+		if _, ok := seen[hash]; ok {
+			return ErrDuplicateTransaction
+		}
+		seen[hash] = struct{}{}
+		hashes = append(hashes, hash)
+	}
+	root := merkletree.CalcMerkleTreeRoot(hashes)
+	if root != b.Header.TransactionsRoot {
+		return ErrTransactionsRoot
+	}
+	return nil
+}
+
+// Bytes get block serialize byte array
+func (b *Block) Bytes() []byte {
+	bf, _ := serialize.ToBytes(b)
+	return bf
+}
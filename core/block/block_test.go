@@ -0,0 +1,191 @@
+package block
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/smartcontract/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func testInvokeTx(tb testing.TB, code []byte) *transaction.Transaction {
+	tx, err := transaction.NewInvokeTx(types.VMCode{VMType: types.NEOVM, Code: code})
+	if err != nil {
+		tb.Fatalf("new invoke tx: %s", err)
+	}
+	return tx
+}
+
+func TestBlockVerify(t *testing.T) {
+	var txs []*transaction.Transaction
+	for i := 0; i < 3; i++ {
+		tx := testInvokeTx(t, []byte{0xFF, byte(i)})
+		txs = append(txs, tx)
+	}
+	head := &Header{
+		Version: 0x01,
+		Height:  1,
+	}
+	blk := NewBlock(head, txs)
+	if err := blk.Verify(); err != nil {
+		t.Errorf("block verify: %s", err)
+	}
+	if blk.Hash() != head.Hash() {
+		t.Errorf("block hash:\n%X\n%X", blk.Hash(), head.Hash())
+	}
+
+	blk.Transactions = txs[:2]
+	if err := blk.Verify(); err != ErrTransactionsRoot {
+		t.Errorf("block verify with wrong body: %v", err)
+	}
+
+	// [a,b,c,c] has the same merkle root as [a,b,c]
+	blk.Transactions = append(txs[:3:3], txs[2])
+	if TransactionsRoot(blk.Transactions) != head.TransactionsRoot {
+		t.Fatalf("merkle root of duplicated last transaction")
+	}
+	if err := blk.Verify(); err != ErrDuplicateTransaction {
+		t.Errorf("block verify with duplicate transaction: %v", err)
+	}
+}
+
+func TestBlockSerialize(t *testing.T) {
+	head := &Header{
+		Version:   0x01,
+		Timestamp: 0xFD,
+		Height:    1024,
+	}
+	blk := NewBlock(head, nil)
+	buf := new(bytes.Buffer)
+	if err := blk.Serialize(buf); err != nil {
+		t.Errorf("block serialize: %s", err)
+	}
+	var blk2 Block
+	if err := blk2.Deserialize(buf); err != nil {
+		t.Errorf("block deserialize: %s", err)
+	}
+	if blk2.Hash() != blk.Hash() {
+		t.Errorf("block deserialize:\n%X\n%X", blk2.Hash(), blk.Hash())
+	}
+	if err := blk2.Verify(); err != nil {
+		t.Errorf("block verify: %s", err)
+	}
+}
+
+func TestHeaderChainID(t *testing.T) {
+	head := &Header{Height: 1, ChainID: 1}
+	legacy := head.Hash()
+	if !bytes.Equal(head.Bytes(), (&Header{Height: 1}).Bytes()) {
+		t.Errorf("legacy header serialize with chain id")
+	}
+	for _, version := range []uint32{0x01, 0xFE} {
+		if (&Header{Version: version}).HasChainID() {
+			t.Errorf("header version 0x%02x in use with chain id", version)
+		}
+	}
+
+	head = &Header{Version: HeaderVersionChainID, Height: 1, ChainID: 1}
+	if head.Hash() == legacy {
+		t.Errorf("header hash with chain id")
+	}
+	var head2 Header
+	if err := head2.Deserialize(bytes.NewReader(head.Bytes())); err != nil {
+		t.Fatalf("header deserialize: %s", err)
+	}
+	if head2.ChainID != 1 || head2.Hash() != head.Hash() {
+		t.Errorf("header deserialize chain id: %d", head2.ChainID)
+	}
+	if (&Header{Version: HeaderVersionChainID, Height: 1, ChainID: 2}).Hash() == head.Hash() {
+		t.Errorf("header hash of other chain")
+	}
+}
+
+func benchmarkBlock(b *testing.B) *Block {
+	var txs []*transaction.Transaction
+	for i := 0; i < 100; i++ {
+		tx := testInvokeTx(b, make([]byte, 64))
+		tx.Nonce = uint32(i)
+		tx.Sigs = []*transaction.Sig{{M: 1, SigData: [][]byte{make([]byte, 64)}}}
+		txs = append(txs, tx)
+	}
+	return NewBlock(&Header{Version: HeaderVersionChainID, Height: 1024}, txs)
+}
+
+func BenchmarkBlockSerialize(b *testing.B) {
+	blk := benchmarkBlock(b)
+	buf := new(bytes.Buffer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := blk.Serialize(buf); err != nil {
+			b.Fatalf("block serialize: %s", err)
+		}
+	}
+}
+
+func BenchmarkBlockDeserialize(b *testing.B) {
+	data := benchmarkBlock(b).Bytes()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var blk Block
+		if err := blk.Deserialize(bytes.NewReader(data)); err != nil {
+			b.Fatalf("block deserialize: %s", err)
+		}
+	}
+}
+
+func BenchmarkHeaderHash(b *testing.B) {
+	head := benchmarkBlock(b).Header
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		head.hash = nil
+		head.Hash()
+	}
+}
+
+func TestBlockJSON(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate public key: %s", err)
+	}
+	txs := []*transaction.Transaction{
+		testInvokeTx(t, []byte{0xFF}),
+	}
+	blk := NewBlock(&Header{
+		Version:          HeaderVersionChainID,
+		ChainID:          1,
+		PrevBlockHash:    common.Uint256{0x01},
+		Timestamp:        0xFD,
+		Height:           1024,
+		ConsensusData:    0xFFFFFFFFFFFFFFFF,
+		ConsensusPayload: []byte{0xFF},
+		NextBookkeeper:   common.Address{0x02},
+		Bookkeepers:      []keypair.PublicKey{pk},
+		SigData:          [][]byte{{0xFF}},
+	}, txs)
+	data, err := json.Marshal(blk)
+	if err != nil {
+		t.Fatalf("block marshal json: %s", err)
+	}
+	hash := blk.Hash()
+	if !strings.Contains(string(data), `"hash":"`+common.Hex(hash[:])+`"`) {
+		t.Errorf("block json without hash:\n%s", data)
+	}
+	var blk2 Block
+	if err := json.Unmarshal(data, &blk2); err != nil {
+		t.Fatalf("block unmarshal json: %s", err)
+	}
+	if !bytes.Equal(blk2.Bytes(), blk.Bytes()) || blk2.Hash() != hash {
+		t.Errorf("block json round trip:\n%s", data)
+	}
+	if err := blk2.Verify(); err != nil {
+		t.Errorf("block verify: %s", err)
+	}
+}
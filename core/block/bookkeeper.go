@@ -0,0 +1,18 @@
+package block
+
+import (
+	"github.com/mileschao/echain/common"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// BookkeeperThreshold get the number of signatures required of n bookkeepers,
+// which tolerates (n-1)/3 faulty bookkeepers
+func BookkeeperThreshold(n int) int {
+	return n - (n-1)/3
+}
+
+// AddressFromBookkeepers get the multi-signature Address of bookkeepers,
+// which is used as Header.NextBookkeeper
+func AddressFromBookkeepers(bookkeepers []keypair.PublicKey) (common.Address, error) {
+	return common.AddressFromMultiPubKeys(BookkeeperThreshold(len(bookkeepers)), bookkeepers)
+}
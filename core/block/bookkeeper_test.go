@@ -0,0 +1,30 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func TestBookkeeperThreshold(t *testing.T) {
+	for n, m := range map[int]int{1: 1, 2: 2, 3: 3, 4: 3, 7: 5, 10: 7} {
+		if BookkeeperThreshold(n) != m {
+			t.Errorf("bookkeeper threshold of %d: %d", n, BookkeeperThreshold(n))
+		}
+	}
+
+	var pks []keypair.PublicKey
+	for i := 0; i < 4; i++ {
+		_, pk, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+		pks = append(pks, pk)
+	}
+	addr, err := AddressFromBookkeepers(pks)
+	if err != nil {
+		t.Fatalf("address from bookkeepers: %s", err)
+	}
+	expect, _ := common.AddressFromMultiPubKeys(3, pks)
+	if addr != expect {
+		t.Errorf("address from bookkeepers: %s", addr.Hex())
+	}
+}
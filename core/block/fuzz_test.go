@@ -0,0 +1,43 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/common/serialize/serializetest"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func fuzzHeader(f *testing.F) *Header {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		f.Fatalf("generate public key: %s", err)
+	}
+	return &Header{
+		Version:          HeaderVersionChainID,
+		ChainID:          1,
+		PrevBlockHash:    common.Uint256{0x01},
+		Timestamp:        0xFD,
+		Height:           1024,
+		ConsensusData:    0xFF,
+		ConsensusPayload: []byte{0xFF},
+		NextBookkeeper:   common.Address{0x02},
+		Bookkeepers:      []keypair.PublicKey{pk},
+		SigData:          [][]byte{{0xFF}},
+	}
+}
+
+func FuzzHeader(f *testing.F) {
+	serializetest.AddSeeds(f, &Header{Height: 1}, fuzzHeader(f))
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Header) })
+}
+
+func FuzzBlock(f *testing.F) {
+	txs := []*transaction.Transaction{
+		testInvokeTx(f, []byte{0xFF}),
+	}
+	serializetest.AddSeeds(f, NewBlock(&Header{Height: 1}, nil), NewBlock(fuzzHeader(f), txs))
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Block) })
+}
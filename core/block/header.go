@@ -1,20 +1,28 @@
 package block
 
 import (
-	"bytes"
 	"crypto/sha256"
-	"encoding/binary"
+	"encoding/json"
 	"io"
 
 	"github.com/mileschao/echain/common/serialize"
 
 	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/signature"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
+const (
+	// HeaderVersionChainID the first version of header with ChainID,
+	// ChainID is neither serialized nor hashed in the earlier versions.
+	// it is above the versions up to 0xFF used by the existing headers
+	HeaderVersionChainID = 0x100
+)
+
 // Header block header
 type Header struct {
 	Version          uint32
+	ChainID          uint32
 	PrevBlockHash    common.Uint256
 	TransactionsRoot common.Uint256
 	BlockRoot        common.Uint256
@@ -30,110 +38,146 @@ type Header struct {
 
 //Serialize implement the Serializable interface
 func (bh *Header) Serialize(w io.Writer) error {
-	binary.Write(w, binary.LittleEndian, bh.Version)
-	bh.PrevBlockHash.Serialize(w)
-	bh.TransactionsRoot.Serialize(w)
-	bh.BlockRoot.Serialize(w)
-	binary.Write(w, binary.LittleEndian, bh.Timestamp)
-	binary.Write(w, binary.LittleEndian, bh.Height)
-	binary.Write(w, binary.LittleEndian, bh.ConsensusData)
-	var cpvb = serialize.VarBytes{Bytes: bh.ConsensusPayload, Len: uint64(len(bh.ConsensusPayload))}
-	cpvb.Serialize(w)
-	bh.NextBookkeeper.Serialize(w)
-
-	var bkvu = serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(bh.Bookkeepers))),
-		Value:    uint64(len(bh.Bookkeepers)),
-	}
-	bkvu.Serialize(w)
+	return serialize.WriteTo(w, bh)
+}
+
+//Deserialize implement Serializable interface
+func (bh *Header) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, bh)
+}
 
+// SerializeSink implement SinkSerializable interface
+func (bh *Header) SerializeSink(sink *serialize.Sink) error {
+	if err := bh.SerializeUnsignedSink(sink); err != nil {
+		return err
+	}
+	sink.WriteVarUint(uint64(len(bh.Bookkeepers)))
 	for _, bk := range bh.Bookkeepers {
-		bkb := keypair.SerializePublicKey(bk)
-		var bkvb = serialize.VarBytes{
-			Len:   uint64(len(bkb)),
-			Bytes: bkb,
-		}
-		bkvb.Serialize(w)
+		sink.WriteVarBytes(keypair.SerializePublicKey(bk))
 	}
-
-	var sigvu = serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(bh.SigData))),
-		Value:    uint64(len(bh.SigData)),
+	sink.WriteVarUint(uint64(len(bh.SigData)))
+	for _, sg := range bh.SigData {
+		sink.WriteVarBytes(sg)
 	}
-	sigvu.Serialize(w)
+	return nil
+}
 
-	for _, sg := range bh.SigData {
-		var sgb = serialize.VarBytes{
-			Len:   uint64(len(sg)),
-			Bytes: sg,
-		}
-		sgb.Serialize(w)
+// SerializeUnsigned serialize the header without Bookkeepers and SigData,
+// which is the data hashed and signed by bookkeepers
+func (bh *Header) SerializeUnsigned(w io.Writer) error {
+	sink := serialize.AcquireSink()
+	defer serialize.ReleaseSink(sink)
+	if err := bh.SerializeUnsignedSink(sink); err != nil {
+		return err
 	}
+	_, err := w.Write(sink.Bytes())
+	return err
+}
 
+// SerializeUnsignedSink serialize the header without Bookkeepers and SigData into sink,
+// see SerializeUnsigned
+func (bh *Header) SerializeUnsignedSink(sink *serialize.Sink) error {
+	sink.WriteUint32(bh.Version)
+	if bh.HasChainID() {
+		sink.WriteUint32(bh.ChainID)
+	}
+	sink.WriteBytes(bh.PrevBlockHash[:])
+	sink.WriteBytes(bh.TransactionsRoot[:])
+	sink.WriteBytes(bh.BlockRoot[:])
+	sink.WriteUint32(bh.Timestamp)
+	sink.WriteUint32(bh.Height)
+	sink.WriteUint64(bh.ConsensusData)
+	sink.WriteVarBytes(bh.ConsensusPayload)
+	sink.WriteBytes(bh.NextBookkeeper[:])
 	return nil
 }
 
-//Deserialize implement Serializable interface
-func (bh *Header) Deserialize(r io.Reader) error {
-	binary.Read(r, binary.LittleEndian, &bh.Version)
-	bh.PrevBlockHash.Deserialize(r)
-	bh.TransactionsRoot.Deserialize(r)
-	bh.BlockRoot.Deserialize(r)
-	binary.Read(r, binary.LittleEndian, &bh.Timestamp)
-	binary.Read(r, binary.LittleEndian, &bh.Height)
-	binary.Read(r, binary.LittleEndian, &bh.ConsensusData)
-	var cpvb serialize.VarBytes
-	cpvb.Deserialize(r)
-	bh.ConsensusPayload = cpvb.Bytes
-	bh.NextBookkeeper.Deserialize(r)
-
-	var bkvu serialize.VarUint
-	bkvu.Deserialize(r)
-
-	for i := uint64(0); i < bkvu.Value; i++ {
-
-		var bkvb serialize.VarBytes
-		bkvb.Deserialize(r)
-		kp, err := keypair.DeserializePublicKey(bkvb.Bytes[:])
+// DeserializeSource implement SinkSerializable interface
+func (bh *Header) DeserializeSource(source *serialize.Source) error {
+	var err error
+	if bh.Version, err = source.ReadUint32(); err != nil {
+		return err
+	}
+	bh.ChainID = 0
+	if bh.HasChainID() {
+		if bh.ChainID, err = source.ReadUint32(); err != nil {
+			return err
+		}
+	}
+	if err := source.ReadFull(bh.PrevBlockHash[:]); err != nil {
+		return err
+	}
+	if err := source.ReadFull(bh.TransactionsRoot[:]); err != nil {
+		return err
+	}
+	if err := source.ReadFull(bh.BlockRoot[:]); err != nil {
+		return err
+	}
+	if bh.Timestamp, err = source.ReadUint32(); err != nil {
+		return err
+	}
+	if bh.Height, err = source.ReadUint32(); err != nil {
+		return err
+	}
+	if bh.ConsensusData, err = source.ReadUint64(); err != nil {
+		return err
+	}
+	if bh.ConsensusPayload, err = source.ReadVarBytes(serialize.MaxVarBytesLen); err != nil {
+		return err
+	}
+	if err := source.ReadFull(bh.NextBookkeeper[:]); err != nil {
+		return err
+	}
+
+	n, err := source.ReadVarUint(common.MAX_MULTI_PUBKEYS)
+	if err != nil {
+		return err
+	}
+	bh.Bookkeepers = make([]keypair.PublicKey, 0, n)
+	for i := uint64(0); i < n; i++ {
+		bkb, err := source.ReadVarBytes(signature.MaxPubKeySize)
 		if err != nil {
-			// TODO: error handle
-			continue
+			return err
+		}
+		kp, err := signature.DeserializePublicKey(bkb)
+		if err != nil {
+			return err
 		}
 		bh.Bookkeepers = append(bh.Bookkeepers, kp)
 	}
 
-	var sigvu serialize.VarUint
-	sigvu.Deserialize(r)
-
-	for i := uint64(0); i < sigvu.Value; i++ {
-		var sgb serialize.VarBytes
-		sgb.Deserialize(r)
-		bh.SigData = append(bh.SigData, sgb.Bytes)
+	n, err = source.ReadVarUint(common.MAX_MULTI_PUBKEYS)
+	if err != nil {
+		return err
 	}
+	bh.SigData = make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		sg, err := source.ReadVarBytes(signature.MaxSignatureSize)
+		if err != nil {
+			return err
+		}
+		bh.SigData = append(bh.SigData, sg)
+	}
+	bh.hash = nil
 	return nil
 }
 
+// HasChainID whether the ChainID is serialized and hashed by the version of header
+func (bh *Header) HasChainID() bool {
+	return bh.Version >= HeaderVersionChainID
+}
+
 // Hash get the hash value of header
 func (bh *Header) Hash() common.Uint256 {
 	if bh.hash != nil {
 		return *bh.hash
 	}
-	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.LittleEndian, bh.Version)
-	binary.Write(buf, binary.LittleEndian, bh.PrevBlockHash)
-	binary.Write(buf, binary.LittleEndian, bh.TransactionsRoot)
-	bh.BlockRoot.Serialize(buf)
-	binary.Write(buf, binary.LittleEndian, bh.Timestamp)
-	binary.Write(buf, binary.LittleEndian, bh.Height)
-	binary.Write(buf, binary.LittleEndian, bh.ConsensusData)
-	var cpvb = &serialize.VarBytes{
-		Len:   uint64(len(bh.ConsensusPayload)),
-		Bytes: bh.ConsensusPayload,
-	}
-	cpvb.Serialize(buf)
-	bh.NextBookkeeper.Serialize(buf)
-
-	tmp := sha256.Sum256(buf.Bytes())
+	sink := serialize.AcquireSink()
+	defer serialize.ReleaseSink(sink)
+	if err := bh.SerializeUnsignedSink(sink); err != nil {
+		return common.UINT256_EMPTY
+	}
+	tmp := sha256.Sum256(sink.Bytes())
 	hash := common.Uint256(sha256.Sum256(tmp[:]))
 	bh.hash = &hash
 
@@ -142,7 +186,76 @@ func (bh *Header) Hash() common.Uint256 {
 
 // Bytes get header serialze byte array
 func (bh *Header) Bytes() []byte {
-	bf := new(bytes.Buffer)
-	bh.Serialize(bf)
-	return bf.Bytes()
+	b, _ := serialize.ToBytes(bh)
+	return b
+}
+
+type headerJSON struct {
+	Version          uint32            `json:"version"`
+	ChainID          uint32            `json:"chain_id"`
+	PrevBlockHash    common.Uint256    `json:"prev_block_hash"`
+	TransactionsRoot common.Uint256    `json:"transactions_root"`
+	BlockRoot        common.Uint256    `json:"block_root"`
+	Timestamp        uint32            `json:"timestamp"`
+	Height           uint32            `json:"height"`
+	ConsensusData    uint64            `json:"consensus_data"`
+	ConsensusPayload common.HexBytes   `json:"consensus_payload"`
+	NextBookkeeper   common.Address    `json:"next_bookkeeper"`
+	Bookkeepers      []string          `json:"bookkeepers"`
+	SigData          []common.HexBytes `json:"sig_data"`
+	Hash             common.Uint256    `json:"hash"`
+}
+
+// MarshalJSON implement json.Marshaler
+// hash is informative only, which is ignored by UnmarshalJSON
+func (bh Header) MarshalJSON() ([]byte, error) {
+	hj := &headerJSON{
+		Version:          bh.Version,
+		ChainID:          bh.ChainID,
+		PrevBlockHash:    bh.PrevBlockHash,
+		TransactionsRoot: bh.TransactionsRoot,
+		BlockRoot:        bh.BlockRoot,
+		Timestamp:        bh.Timestamp,
+		Height:           bh.Height,
+		ConsensusData:    bh.ConsensusData,
+		ConsensusPayload: bh.ConsensusPayload,
+		NextBookkeeper:   bh.NextBookkeeper,
+		Bookkeepers:      signature.PublicKeysToHex(bh.Bookkeepers),
+		SigData:          make([]common.HexBytes, 0, len(bh.SigData)),
+		Hash:             bh.Hash(),
+	}
+	for _, sg := range bh.SigData {
+		hj.SigData = append(hj.SigData, sg)
+	}
+	return json.Marshal(hj)
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+func (bh *Header) UnmarshalJSON(data []byte) error {
+	var hj headerJSON
+	if err := json.Unmarshal(data, &hj); err != nil {
+		return err
+	}
+	bookkeepers, err := signature.PublicKeysFromHex(hj.Bookkeepers)
+	if err != nil {
+		return err
+	}
+	*bh = Header{
+		Version:          hj.Version,
+		ChainID:          hj.ChainID,
+		PrevBlockHash:    hj.PrevBlockHash,
+		TransactionsRoot: hj.TransactionsRoot,
+		BlockRoot:        hj.BlockRoot,
+		Timestamp:        hj.Timestamp,
+		Height:           hj.Height,
+		ConsensusData:    hj.ConsensusData,
+		ConsensusPayload: hj.ConsensusPayload,
+		NextBookkeeper:   hj.NextBookkeeper,
+		Bookkeepers:      bookkeepers,
+		SigData:          make([][]byte, 0, len(hj.SigData)),
+	}
+	for _, sg := range hj.SigData {
+		bh.SigData = append(bh.SigData, sg)
+	}
+	return nil
 }
@@ -2,6 +2,8 @@ package block
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"reflect"
 	"testing"
 
 	"github.com/ontio/ontology-crypto/keypair"
@@ -71,3 +73,82 @@ func TestHeaderSerialze(t *testing.T) {
 		t.Errorf("header deserialze: %s", err)
 	}
 }
+
+func TestHeaderSignedFields(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate public key: %s", err)
+	}
+	newHeader := func() *Header {
+		return &Header{
+			Version:          HeaderVersionChainID,
+			ChainID:          1,
+			PrevBlockHash:    common.Uint256{0x01},
+			TransactionsRoot: common.Uint256{0x02},
+			BlockRoot:        common.Uint256{0x03},
+			Timestamp:        0xFD,
+			Height:           1024,
+			ConsensusData:    0xFF,
+			ConsensusPayload: []byte{0xFF},
+			NextBookkeeper:   common.Address{0x04},
+			Bookkeepers:      []keypair.PublicKey{pk},
+			SigData:          [][]byte{{0xFF}},
+		}
+	}
+	signed := map[string]func(h *Header){
+		"Version":          func(h *Header) { h.Version++ },
+		"ChainID":          func(h *Header) { h.ChainID++ },
+		"PrevBlockHash":    func(h *Header) { h.PrevBlockHash[0]++ },
+		"TransactionsRoot": func(h *Header) { h.TransactionsRoot[0]++ },
+		"BlockRoot":        func(h *Header) { h.BlockRoot[0]++ },
+		"Timestamp":        func(h *Header) { h.Timestamp++ },
+		"Height":           func(h *Header) { h.Height++ },
+		"ConsensusData":    func(h *Header) { h.ConsensusData++ },
+		"ConsensusPayload": func(h *Header) { h.ConsensusPayload = []byte{0xFE} },
+		"NextBookkeeper":   func(h *Header) { h.NextBookkeeper[0]++ },
+	}
+	unsigned := map[string]func(h *Header){
+		"Bookkeepers": func(h *Header) { h.Bookkeepers = append(h.Bookkeepers, pk) },
+		"SigData":     func(h *Header) { h.SigData = [][]byte{{0xFE}} },
+	}
+	typ := reflect.TypeOf(Header{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if typ.Field(i).PkgPath != "" {
+			continue
+		}
+		if signed[name] == nil && unsigned[name] == nil {
+			t.Errorf("header field %s is neither signed nor unsigned", name)
+		}
+	}
+
+	head := newHeader()
+	buf := new(bytes.Buffer)
+	if err := head.SerializeUnsigned(buf); err != nil {
+		t.Fatalf("header serialize unsigned: %s", err)
+	}
+	tmp := sha256.Sum256(buf.Bytes())
+	if head.Hash() != common.Uint256(sha256.Sum256(tmp[:])) {
+		t.Errorf("header hash is not of unsigned data")
+	}
+	if !bytes.HasPrefix(head.Bytes(), buf.Bytes()) {
+		t.Errorf("header serialize does not start with unsigned data")
+	}
+	for name, mutate := range signed {
+		h := newHeader()
+		mutate(h)
+		if h.Hash() == head.Hash() {
+			t.Errorf("header hash unchanged with signed field %s", name)
+		}
+	}
+	for name, mutate := range unsigned {
+		h := newHeader()
+		mutate(h)
+		if h.Hash() != head.Hash() {
+			t.Errorf("header hash changed with unsigned field %s", name)
+		}
+		if bytes.Equal(h.Bytes(), head.Bytes()) {
+			t.Errorf("header field %s is not serialized", name)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/core/validation"
+	"github.com/ontio/ontology-crypto/keypair"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultBlockInterval default seconds between blocks
+	DefaultBlockInterval = 6
+)
+
+var (
+	// ErrNoBookkeeper genesis config without bookkeeper
+	ErrNoBookkeeper = errors.New("no bookkeeper in genesis config")
+	// ErrDuplicatedBookkeeper the same bookkeeper appears more than once
+	ErrDuplicatedBookkeeper = errors.New("duplicated bookkeeper in genesis config")
+)
+
+// Config chain configuration, i.e. in JSON
+//
+//	{
+//	    "chain_id": 1,
+//	    "timestamp": 1530000000,
+//	    "block_interval": 6,
+//	    "min_gas_price": 0,
+//	    "min_gas_limit": 20000,
+//	    "max_gas_limit": 100000000,
+//	    "bookkeepers": ["1202..."]
+//	}
+//
+// or the same fields in YAML
+//
+//	chain_id: 1
+//	timestamp: 1530000000
+//	bookkeepers:
+//	  - "1202..."
+//
+// bookkeepers are hex strings of serialized public keys
+type Config struct {
+	ChainID       uint32   `json:"chain_id" yaml:"chain_id"`
+	Timestamp     uint32   `json:"timestamp" yaml:"timestamp"`
+	BlockInterval uint32   `json:"block_interval" yaml:"block_interval"`
+	MinGasPrice   uint64   `json:"min_gas_price" yaml:"min_gas_price"`
+	MinGasLimit   uint64   `json:"min_gas_limit" yaml:"min_gas_limit"`
+	MaxGasLimit   uint64   `json:"max_gas_limit" yaml:"max_gas_limit"`
+	Bookkeepers   []string `json:"bookkeepers" yaml:"bookkeepers"`
+}
+
+// LoadConfig read the chain configuration from file,
+// which is YAML if the extension is .yaml or .yml, otherwise JSON
+func LoadConfig(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return ParseYAMLConfig(data)
+	default:
+		return ParseConfig(data)
+	}
+}
+
+// ParseConfig parse the chain configuration from JSON,
+// the fields not set are filled with default values
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := newConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseYAMLConfig parse the chain configuration from YAML,
+// the fields not set are filled with default values
+func ParseYAMLConfig(data []byte) (*Config, error) {
+	cfg := newConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newConfig create the config with default values
+func newConfig() *Config {
+	return &Config{
+		BlockInterval: DefaultBlockInterval,
+		MinGasPrice:   validation.DefaultConfig.MinGasPrice,
+		MinGasLimit:   validation.DefaultConfig.MinGasLimit,
+		MaxGasLimit:   validation.DefaultConfig.MaxGasLimit,
+	}
+}
+
+// BookkeeperKeys get the public keys of initial bookkeepers
+func (cfg *Config) BookkeeperKeys() ([]keypair.PublicKey, error) {
+	if len(cfg.Bookkeepers) == 0 {
+		return nil, ErrNoBookkeeper
+	}
+	pks := make([]keypair.PublicKey, 0, len(cfg.Bookkeepers))
+	seen := make(map[string]bool, len(cfg.Bookkeepers))
+	for _, bk := range cfg.Bookkeepers {
+		data, err := common.HexToBytes(bk)
+		if err != nil {
+			return nil, err
+		}
+		pk, err := signature.DeserializePublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+		key := string(keypair.SerializePublicKey(pk))
+		if seen[key] {
+			return nil, ErrDuplicatedBookkeeper
+		}
+		seen[key] = true
+		pks = append(pks, pk)
+	}
+	return pks, nil
+}
+
+// ValidationConfig get the bounds of transaction validation
+func (cfg *Config) ValidationConfig() validation.Config {
+	vc := validation.DefaultConfig
+	vc.ChainID = cfg.ChainID
+	vc.MinGasPrice = cfg.MinGasPrice
+	vc.MinGasLimit = cfg.MinGasLimit
+	vc.MaxGasLimit = cfg.MaxGasLimit
+	return vc
+}
+
+// ConsensusPayload get the chain parameters committed to genesis block,
+// which is BlockInterval + MinGasPrice + MinGasLimit + MaxGasLimit
+func (cfg *Config) ConsensusPayload() []byte {
+	sink := serialize.NewSink(make([]byte, 0, 28))
+	sink.WriteUint32(cfg.BlockInterval)
+	sink.WriteUint64(cfg.MinGasPrice)
+	sink.WriteUint64(cfg.MinGasLimit)
+	sink.WriteUint64(cfg.MaxGasLimit)
+	return sink.Bytes()
+}
+
+// BuildGenesisBlock build the block at height 0 with an Bookkeeper transaction of every
+// initial bookkeeper, the same config always derives the same block hash.
+// the Bookkeeper transactions are self issued without cert, the block is checked by
+// validation.ValidateGenesisBlock and the bookkeepers are set by ledger.BookkeeperStore.ApplyBlock
+func BuildGenesisBlock(cfg *Config) (*block.Block, error) {
+	pks, err := cfg.BookkeeperKeys()
+	if err != nil {
+		return nil, err
+	}
+	next, err := block.AddressFromBookkeepers(pks)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*transaction.Transaction, 0, len(pks))
+	for _, pk := range pks {
+		tx := &transaction.Transaction{
+			TxType:   transaction.Bookkeeper,
+			GasPrice: cfg.MinGasPrice,
+			Payer:    common.AddressFromPubKey(pk),
+			Payload: &payload.Bookkeeper{
+				PubKey: pk,
+				Action: payload.BookkeeperActionADD,
+				Issuer: pk,
+			},
+		}
+		tx.SetChainID(cfg.ChainID)
+		txs = append(txs, tx)
+	}
+	header := &block.Header{
+		Version:          block.HeaderVersionChainID,
+		ChainID:          cfg.ChainID,
+		Timestamp:        cfg.Timestamp,
+		Height:           0,
+		ConsensusPayload: cfg.ConsensusPayload(),
+		NextBookkeeper:   next,
+		Bookkeepers:      pks,
+	}
+	return block.NewBlock(header, txs), nil
+}
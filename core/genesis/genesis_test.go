@@ -0,0 +1,207 @@
+package genesis
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/ledger"
+	"github.com/mileschao/echain/core/validation"
+	"github.com/mileschao/echain/storage/memdb"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func newTestConfigData(t *testing.T, chainID uint32, n int) []byte {
+	var bks string
+	for i := 0; i < n; i++ {
+		_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+		if err != nil {
+			t.Fatalf("generate key pair: %s", err)
+		}
+		if i > 0 {
+			bks += ","
+		}
+		bks += fmt.Sprintf("%q", common.Hex(keypair.SerializePublicKey(pk)))
+	}
+	return []byte(fmt.Sprintf(`{"chain_id": %d, "timestamp": 1530000000, "min_gas_price": 1, "bookkeepers": [%s]}`, chainID, bks))
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genesis")
+	if err != nil {
+		t.Fatalf("temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "genesis.json")
+	if err := ioutil.WriteFile(file, newTestConfigData(t, 1, 4), 0644); err != nil {
+		t.Fatalf("write config: %s", err)
+	}
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %s", err)
+	}
+	if cfg.ChainID != 1 || cfg.BlockInterval != DefaultBlockInterval || len(cfg.Bookkeepers) != 4 {
+		t.Errorf("load config: %+v", cfg)
+	}
+	vc := cfg.ValidationConfig()
+	if vc.ChainID != 1 || vc.MinGasPrice != 1 || vc.MinGasLimit != cfg.MinGasLimit || vc.MaxTxSize == 0 {
+		t.Errorf("validation config: %+v", vc)
+	}
+}
+
+func TestLoadYAMLConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genesis")
+	if err != nil {
+		t.Fatalf("temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	jsonCfg, err := ParseConfig(newTestConfigData(t, 1, 4))
+	if err != nil {
+		t.Fatalf("parse config: %s", err)
+	}
+	data := "chain_id: 1\ntimestamp: 1530000000\nmin_gas_price: 1\nbookkeepers:\n"
+	for _, bk := range jsonCfg.Bookkeepers {
+		data += fmt.Sprintf("  - %q\n", bk)
+	}
+	file := filepath.Join(dir, "genesis.yaml")
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatalf("write config: %s", err)
+	}
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load yaml config: %s", err)
+	}
+	if !reflect.DeepEqual(cfg, jsonCfg) {
+		t.Errorf("yaml config: %+v", cfg)
+	}
+	blk, err := BuildGenesisBlock(cfg)
+	if err != nil {
+		t.Fatalf("build genesis block: %s", err)
+	}
+	if expect, _ := BuildGenesisBlock(jsonCfg); blk.Hash() != expect.Hash() {
+		t.Errorf("genesis block of yaml config")
+	}
+	if _, err := ParseYAMLConfig([]byte("chain_id: [")); err == nil {
+		t.Errorf("parse invalid yaml config")
+	}
+}
+
+func TestBuildGenesisBlock(t *testing.T) {
+	data := newTestConfigData(t, 1, 4)
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		t.Fatalf("parse config: %s", err)
+	}
+	blk, err := BuildGenesisBlock(cfg)
+	if err != nil {
+		t.Fatalf("build genesis block: %s", err)
+	}
+	if blk.Header.Height != 0 || len(blk.Transactions) != 4 {
+		t.Errorf("genesis block: %d, %d", blk.Header.Height, len(blk.Transactions))
+	}
+	if err := blk.Verify(); err != nil {
+		t.Errorf("verify genesis block: %s", err)
+	}
+	next, _ := block.AddressFromBookkeepers(blk.Header.Bookkeepers)
+	if blk.Header.NextBookkeeper != next {
+		t.Errorf("genesis next bookkeeper: %s", blk.Header.NextBookkeeper.Hex())
+	}
+
+	cfg2, _ := ParseConfig(data)
+	blk2, err := BuildGenesisBlock(cfg2)
+	if err != nil || blk2.Hash() != blk.Hash() {
+		t.Errorf("genesis block is not deterministic: %v", err)
+	}
+	cfg2.ChainID = 2
+	blk2, err = BuildGenesisBlock(cfg2)
+	if err != nil || blk2.Hash() == blk.Hash() {
+		t.Errorf("genesis block of other chain: %v", err)
+	}
+
+	for name, change := range map[string]func(cfg *Config){
+		"block interval": func(cfg *Config) { cfg.BlockInterval++ },
+		"min gas price":  func(cfg *Config) { cfg.MinGasPrice++ },
+		"min gas limit":  func(cfg *Config) { cfg.MinGasLimit++ },
+		"max gas limit":  func(cfg *Config) { cfg.MaxGasLimit++ },
+	} {
+		cfg2, _ := ParseConfig(data)
+		change(cfg2)
+		blk2, err := BuildGenesisBlock(cfg2)
+		if err != nil || blk2.Hash() == blk.Hash() {
+			t.Errorf("genesis block of other %s: %v", name, err)
+		}
+	}
+}
+
+func TestApplyGenesisBlock(t *testing.T) {
+	cfg, err := ParseConfig(newTestConfigData(t, 1, 4))
+	if err != nil {
+		t.Fatalf("parse config: %s", err)
+	}
+	blk, err := BuildGenesisBlock(cfg)
+	if err != nil {
+		t.Fatalf("build genesis block: %s", err)
+	}
+	vc := cfg.ValidationConfig()
+	if err := validation.ValidateGenesisBlock(blk, &vc); err != nil {
+		t.Fatalf("validate genesis block: %s", err)
+	}
+	for _, tx := range blk.Transactions {
+		if err := validation.ValidateTransaction(tx, &vc); err == nil {
+			t.Errorf("genesis transaction without cert is valid out of genesis block")
+		}
+	}
+	other := vc
+	other.ChainID = 2
+	if err := validation.ValidateGenesisBlock(blk, &other); err == nil {
+		t.Errorf("genesis block of other chain is valid")
+	}
+
+	pks, _ := cfg.BookkeeperKeys()
+	store := memdb.NewStore()
+	bks := ledger.NewBookkeeperStore(store)
+	// applied to empty store, twice, and after InitBookkeepers
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			if err := bks.InitBookkeepers(pks); err != nil {
+				t.Fatalf("init bookkeepers: %s", err)
+			}
+		}
+		if err := bks.ApplyBlock(blk); err != nil {
+			t.Fatalf("apply genesis block %d: %s", i, err)
+		}
+		bookkeepers, err := bks.GetBookkeepers(0)
+		if err != nil || len(bookkeepers) != len(pks) {
+			t.Fatalf("genesis bookkeepers: %d, %v", len(bookkeepers), err)
+		}
+		for j, pk := range pks {
+			if !keypair.ComparePublicKey(pk, bookkeepers[j]) {
+				t.Errorf("genesis bookkeeper %d", j)
+			}
+		}
+	}
+	if err := ledger.NewBlockStore(store).SaveBlock(blk); err != nil {
+		t.Errorf("save genesis block: %s", err)
+	}
+}
+
+func TestBuildGenesisBlockInvalidConfig(t *testing.T) {
+	cfg, _ := ParseConfig([]byte(`{"chain_id": 1}`))
+	if _, err := BuildGenesisBlock(cfg); err != ErrNoBookkeeper {
+		t.Errorf("build genesis without bookkeeper: %v", err)
+	}
+	cfg.Bookkeepers = []string{"zz"}
+	if _, err := BuildGenesisBlock(cfg); err == nil {
+		t.Errorf("build genesis with invalid bookkeeper")
+	}
+	cfg, _ = ParseConfig(newTestConfigData(t, 1, 1))
+	cfg.Bookkeepers = append(cfg.Bookkeepers, cfg.Bookkeepers[0])
+	if _, err := BuildGenesisBlock(cfg); err != ErrDuplicatedBookkeeper {
+		t.Errorf("build genesis with duplicated bookkeeper: %v", err)
+	}
+}
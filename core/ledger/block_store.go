@@ -0,0 +1,330 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/merkletree"
+	"github.com/mileschao/echain/storage"
+)
+
+var (
+	// ErrBlockHeight the block to be saved is not the next block of current block
+	ErrBlockHeight = errors.New("block height is not the next of current block")
+	// ErrPrevBlockHash the block to be saved is not linked to current block
+	ErrPrevBlockHash = errors.New("previous block hash is not the current block")
+)
+
+// BlockStore persist blocks into PersistStorage with key schema below:
+// DATA_HEADER + block hash             => block header
+// DATA_BLOCK_TX_LIST + block hash      => transaction hash list of block
+// DATA_TRANSACTION + transaction hash  => block height + transaction
+// IX_HEADER_HASH_LIST + block height   => block hash
+// SYS_CURRENT_BLOCK                    => current block hash + current block height
+// SYS_BLOCK_MERKLE_TREE                => state of block merkle tree
+type BlockStore struct {
+	store      storage.PersistStorage
+	merkleTree *merkletree.MerkleHeap
+	hashStore  merkletree.HashStorage
+}
+
+// NewBlockStore create an new block store on the persist storage
+func NewBlockStore(store storage.PersistStorage) *BlockStore {
+	return &BlockStore{
+		store: store,
+	}
+}
+
+// InitBlockMerkleTree restore the block merkle tree from SYS_BLOCK_MERKLE_TREE,
+// the hashed nodes of the tree are stored in file of path, which is opened with the
+// leaf size stored, thus the hashes appended but not committed are dropped.
+// once initialized, transactions root of every block saved is added into the tree
+func (bs *BlockStore) InitBlockMerkleTree(path string) error {
+	var leafSize uint64
+	data, err := bs.store.Get(blockMerkleTreeKey())
+	if err == nil {
+		if len(data) < 8 {
+			return io.ErrUnexpectedEOF
+		}
+		leafSize = binary.LittleEndian.Uint64(data)
+	} else if err != storage.ErrNotFound {
+		return err
+	}
+	hashStore, err := merkletree.NewFileHashStorage(path, leafSize)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		bs.merkleTree = merkletree.NewMerkleStorage(0, nil, hashStore)
+		bs.hashStore = hashStore
+		return nil
+	}
+	tree, err := merkletree.LoadMerkleStorage(bytes.NewReader(data), hashStore)
+	if err != nil {
+		hashStore.Close()
+		return err
+	}
+	bs.merkleTree = tree
+	bs.hashStore = hashStore
+	return nil
+}
+
+// Close release the hash storage of block merkle tree,
+// the persist storage is owned by caller
+func (bs *BlockStore) Close() {
+	if bs.hashStore != nil {
+		bs.hashStore.Close()
+		bs.hashStore = nil
+	}
+}
+
+// BlockMerkleTree get the block merkle tree, nil if not initialized
+func (bs *BlockStore) BlockMerkleTree() *merkletree.MerkleHeap {
+	return bs.merkleTree
+}
+
+// SaveBlock persist the block atomically
+// the block must be the next block linked to current block, or the first block when store is empty
+func (bs *BlockStore) SaveBlock(blk *block.Block) error {
+	if err := blk.Verify(); err != nil {
+		return err
+	}
+	currHash, height, err := bs.GetCurrentBlock()
+	if err == storage.ErrNotFound {
+		if blk.Header.Height != 0 {
+			return ErrBlockHeight
+		}
+	} else if err != nil {
+		return err
+	} else if blk.Header.Height != height+1 {
+		return ErrBlockHeight
+	} else if blk.Header.PrevBlockHash != currHash {
+		return ErrPrevBlockHash
+	}
+
+	blockHash := blk.Hash()
+	txHashes := new(bytes.Buffer)
+	var txvu = &serialize.VarUint{
+		UintType: serialize.GetUintTypeByValue(uint64(len(blk.Transactions))),
+		Value:    uint64(len(blk.Transactions)),
+	}
+	if err := txvu.Serialize(txHashes); err != nil {
+		return err
+	}
+	txKeys := make([][]byte, 0, len(blk.Transactions))
+	txValues := make([][]byte, 0, len(blk.Transactions))
+	for _, tx := range blk.Transactions {
+		txHash := tx.Hash()
+		if err := txHash.Serialize(txHashes); err != nil {
+			return err
+		}
+		value := new(bytes.Buffer)
+		binary.Write(value, binary.LittleEndian, blk.Header.Height)
+		if err := tx.Serialize(value); err != nil {
+			return err
+		}
+		txKeys = append(txKeys, transactionKey(txHash))
+		txValues = append(txValues, value.Bytes())
+	}
+
+	current := new(bytes.Buffer)
+	blockHash.Serialize(current)
+	binary.Write(current, binary.LittleEndian, blk.Header.Height)
+
+	// the new leaf is staged on a copy of tree, which is swapped in after committed.
+	// its hashes are appended to hash storage before commit, thus the storage is never
+	// behind the committed tree, and they are dropped if commit fails
+	var tree *merkletree.MerkleHeap
+	treeState := new(bytes.Buffer)
+	if bs.merkleTree != nil {
+		tree = bs.merkleTree.Copy()
+		tree.AddLeaf(blk.Header.TransactionsRoot)
+		if err := tree.Serialize(treeState); err != nil {
+			bs.merkleTree.Revert()
+			return err
+		}
+	}
+
+	bs.store.NewBatch()
+	bs.store.BatchPut(headerKey(blockHash), blk.Header.Bytes())
+	for i, key := range txKeys {
+		bs.store.BatchPut(key, txValues[i])
+	}
+	bs.store.BatchPut(blockKey(blockHash), txHashes.Bytes())
+	bs.store.BatchPut(blockHashKey(blk.Header.Height), blockHash[:])
+	bs.store.BatchPut(currentBlockKey(), current.Bytes())
+	if tree != nil {
+		bs.store.BatchPut(blockMerkleTreeKey(), treeState.Bytes())
+	}
+	if err := bs.store.BatchCommit(); err != nil {
+		if tree != nil {
+			bs.merkleTree.Revert()
+		}
+		return err
+	}
+	if tree != nil {
+		bs.merkleTree = tree
+	}
+	return nil
+}
+
+// GetCurrentBlock get hash and height of current block
+// storage.ErrNotFound returned when no block saved
+func (bs *BlockStore) GetCurrentBlock() (common.Uint256, uint32, error) {
+	data, err := bs.store.Get(currentBlockKey())
+	if err != nil {
+		return common.UINT256_EMPTY, 0, err
+	}
+	r := bytes.NewReader(data)
+	var hash common.Uint256
+	if err := hash.Deserialize(r); err != nil {
+		return common.UINT256_EMPTY, 0, err
+	}
+	var height uint32
+	if err := binary.Read(r, binary.LittleEndian, &height); err != nil {
+		return common.UINT256_EMPTY, 0, err
+	}
+	return hash, height, nil
+}
+
+// GetBlockHash get block hash by block height
+func (bs *BlockStore) GetBlockHash(height uint32) (common.Uint256, error) {
+	data, err := bs.store.Get(blockHashKey(height))
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	var hash common.Uint256
+	if err := hash.FromBytes(data); err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	return hash, nil
+}
+
+// GetHeader get block header by block hash
+func (bs *BlockStore) GetHeader(blockHash common.Uint256) (*block.Header, error) {
+	data, err := bs.store.Get(headerKey(blockHash))
+	if err != nil {
+		return nil, err
+	}
+	header := new(block.Header)
+	if err := header.DeserializeSource(serialize.NewSource(data)); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// GetBlock get block by block hash
+func (bs *BlockStore) GetBlock(blockHash common.Uint256) (*block.Block, error) {
+	header, err := bs.GetHeader(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := bs.store.Get(blockKey(blockHash))
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(data)
+	var txvu serialize.VarUint
+	if err := txvu.Deserialize(r); err != nil {
+		return nil, err
+	}
+	txs := make([]*transaction.Transaction, 0, txvu.Value)
+	for i := uint64(0); i < txvu.Value; i++ {
+		var txHash common.Uint256
+		if err := txHash.Deserialize(r); err != nil {
+			return nil, err
+		}
+		tx, _, err := bs.GetTransaction(txHash)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return &block.Block{
+		Header:       header,
+		Transactions: txs,
+	}, nil
+}
+
+// GetBlockByHeight get block by block height
+func (bs *BlockStore) GetBlockByHeight(height uint32) (*block.Block, error) {
+	blockHash, err := bs.GetBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+	return bs.GetBlock(blockHash)
+}
+
+// GetTransaction get transaction and the height of block which contains it by transaction hash
+func (bs *BlockStore) GetTransaction(txHash common.Uint256) (*transaction.Transaction, uint32, error) {
+	data, err := bs.store.Get(transactionKey(txHash))
+	if err != nil {
+		return nil, 0, err
+	}
+	source := serialize.NewSource(data)
+	height, err := source.ReadUint32()
+	if err != nil {
+		return nil, 0, err
+	}
+	tx := new(transaction.Transaction)
+	if err := tx.DeserializeSource(source); err != nil {
+		return nil, 0, err
+	}
+	return tx, height, nil
+}
+
+// GetTransactionHeight get the height of block which contains the transaction
+func (bs *BlockStore) GetTransactionHeight(txHash common.Uint256) (uint32, error) {
+	data, err := bs.store.Get(transactionKey(txHash))
+	if err != nil {
+		return 0, err
+	}
+	return readHeight(bytes.NewReader(data))
+}
+
+// ContainTransaction check whether the transaction has been saved
+func (bs *BlockStore) ContainTransaction(txHash common.Uint256) (bool, error) {
+	return bs.store.Has(transactionKey(txHash))
+}
+
+func readHeight(r io.Reader) (uint32, error) {
+	var height uint32
+	if err := binary.Read(r, binary.LittleEndian, &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func headerKey(blockHash common.Uint256) []byte {
+	return append([]byte{byte(storage.DATA_HEADER)}, blockHash[:]...)
+}
+
+func blockKey(blockHash common.Uint256) []byte {
+	return append([]byte{byte(storage.DATA_BLOCK_TX_LIST)}, blockHash[:]...)
+}
+
+func transactionKey(txHash common.Uint256) []byte {
+	return append([]byte{byte(storage.DATA_TRANSACTION)}, txHash[:]...)
+}
+
+// height is encoded with big endian to keep the keys in order
+func blockHashKey(height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(storage.IX_HEADER_HASH_LIST)
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
+
+func currentBlockKey() []byte {
+	return []byte{byte(storage.SYS_CURRENT_BLOCK)}
+}
+
+func blockMerkleTreeKey() []byte {
+	return []byte{byte(storage.SYS_BLOCK_MERKLE_TREE)}
+}
@@ -0,0 +1,234 @@
+package ledger
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/merkletree"
+	"github.com/mileschao/echain/smartcontract/types"
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/leveldb"
+	"github.com/mileschao/echain/storage/memdb"
+)
+
+func newTestBlockStore(t *testing.T) (*BlockStore, func()) {
+	store := memdb.NewStore()
+	return NewBlockStore(store), func() {
+		store.Close()
+	}
+}
+
+func testInvokeTx(t *testing.T, code []byte) *transaction.Transaction {
+	tx, err := transaction.NewInvokeTx(types.VMCode{VMType: types.NEOVM, Code: code})
+	if err != nil {
+		t.Fatalf("new invoke tx: %s", err)
+	}
+	return tx
+}
+
+func TestBlockStoreSaveBlock(t *testing.T) {
+	bs, closer := newTestBlockStore(t)
+	defer closer()
+
+	if _, _, err := bs.GetCurrentBlock(); err != storage.ErrNotFound {
+		t.Errorf("current block of empty store: %v", err)
+	}
+
+	genesis := block.NewBlock(&block.Header{Height: 0}, nil)
+	if err := bs.SaveBlock(genesis); err != nil {
+		t.Errorf("save genesis block: %s", err)
+	}
+
+	tx := testInvokeTx(t, []byte{0xFF})
+	blk := block.NewBlock(&block.Header{
+		PrevBlockHash: genesis.Hash(),
+		Height:        1,
+	}, []*transaction.Transaction{tx})
+	if err := bs.SaveBlock(blk); err != nil {
+		t.Errorf("save block: %s", err)
+	}
+
+	hash, height, err := bs.GetCurrentBlock()
+	if err != nil || hash != blk.Hash() || height != 1 {
+		t.Errorf("current block: %d, %X, %v", height, hash, err)
+	}
+	hash, err = bs.GetBlockHash(0)
+	if err != nil || hash != genesis.Hash() {
+		t.Errorf("block hash by height: %X, %v", hash, err)
+	}
+	header, err := bs.GetHeader(blk.Hash())
+	if err != nil || header.Hash() != blk.Hash() {
+		t.Errorf("header by hash: %v", err)
+	}
+	txHeight, err := bs.GetTransactionHeight(tx.Hash())
+	if err != nil || txHeight != 1 {
+		t.Errorf("transaction height: %d, %v", txHeight, err)
+	}
+	if ok, err := bs.ContainTransaction(tx.Hash()); !ok || err != nil {
+		t.Errorf("contain transaction: %v", err)
+	}
+	tx2, txHeight, err := bs.GetTransaction(tx.Hash())
+	if err != nil || txHeight != 1 || tx2.Hash() != tx.Hash() {
+		t.Errorf("transaction by hash: %d, %v", txHeight, err)
+	}
+	blk2, err := bs.GetBlockByHeight(1)
+	if err != nil || blk2.Hash() != blk.Hash() || len(blk2.Transactions) != 1 {
+		t.Fatalf("block by height: %v", err)
+	}
+	if err := blk2.Verify(); err != nil {
+		t.Errorf("verify stored block: %s", err)
+	}
+	if _, err := bs.GetBlockHash(2); err != storage.ErrNotFound {
+		t.Errorf("block hash not found: %v", err)
+	}
+}
+
+func TestBlockStoreBlockHeight(t *testing.T) {
+	bs, closer := newTestBlockStore(t)
+	defer closer()
+
+	if err := bs.SaveBlock(block.NewBlock(&block.Header{Height: 1}, nil)); err != ErrBlockHeight {
+		t.Errorf("save block without genesis: %v", err)
+	}
+	if err := bs.SaveBlock(block.NewBlock(&block.Header{Height: 0}, nil)); err != nil {
+		t.Errorf("save genesis block: %s", err)
+	}
+	if err := bs.SaveBlock(block.NewBlock(&block.Header{Height: 2}, nil)); err != ErrBlockHeight {
+		t.Errorf("save block with gap: %v", err)
+	}
+	fork := testMerkleBlock(t, 1, common.Uint256{0xFF})
+	if err := bs.SaveBlock(fork); err != ErrPrevBlockHash {
+		t.Errorf("save block of other fork: %v", err)
+	}
+	if _, err := bs.GetBlockHash(1); err != storage.ErrNotFound {
+		t.Errorf("block of other fork is saved: %v", err)
+	}
+}
+
+func testMerkleBlock(t *testing.T, h uint32, prev common.Uint256) *block.Block {
+	tx := testInvokeTx(t, []byte{byte(h)})
+	return block.NewBlock(&block.Header{
+		PrevBlockHash: prev,
+		Height:        h,
+	}, []*transaction.Transaction{tx})
+}
+
+func TestBlockStoreMerkleTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockstore")
+	if err != nil {
+		t.Fatalf("temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	dbDir := filepath.Join(dir, "db")
+	hsFile := filepath.Join(dir, "block.hs")
+
+	store, err := leveldb.NewStore(dbDir)
+	if err != nil {
+		t.Fatalf("new leveldb store: %s", err)
+	}
+	bs := NewBlockStore(store)
+	if err := bs.InitBlockMerkleTree(hsFile); err != nil {
+		t.Errorf("init block merkle tree: %s", err)
+	}
+	var prev common.Uint256
+	for h := uint32(0); h < 5; h++ {
+		blk := testMerkleBlock(t, h, prev)
+		if err := bs.SaveBlock(blk); err != nil {
+			t.Errorf("save block: %s", err)
+		}
+		prev = blk.Hash()
+	}
+	root := bs.BlockMerkleTree().Root()
+	bs.Close()
+	store.Close()
+
+	store, err = leveldb.NewStore(dbDir)
+	if err != nil {
+		t.Fatalf("reopen leveldb store: %s", err)
+	}
+	defer store.Close()
+	bs = NewBlockStore(store)
+	if err := bs.InitBlockMerkleTree(hsFile); err != nil {
+		t.Fatalf("restore block merkle tree: %s", err)
+	}
+	defer bs.Close()
+	if bs.BlockMerkleTree().LeafSize() != 5 || bs.BlockMerkleTree().Root() != root {
+		t.Errorf("restore block merkle tree: %d\n%X\n%X", bs.BlockMerkleTree().LeafSize(), bs.BlockMerkleTree().Root(), root)
+	}
+	if _, err := bs.BlockMerkleTree().InclusionProof(4, 5); err != nil {
+		t.Errorf("inclusion proof of restored tree: %s", err)
+	}
+}
+
+// failCommitStore persist storage failing the next batch commit
+type failCommitStore struct {
+	storage.PersistStorage
+	fail bool
+}
+
+var errCommit = errors.New("commit failed")
+
+func (fs *failCommitStore) BatchCommit() error {
+	if fs.fail {
+		fs.fail = false
+		return errCommit
+	}
+	return fs.PersistStorage.BatchCommit()
+}
+
+func TestBlockStoreCommitFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockstore")
+	if err != nil {
+		t.Fatalf("temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hs, err := merkletree.NewFileHashStorage(filepath.Join(dir, "expect.hs"), 0)
+	if err != nil {
+		t.Fatalf("new file hash storage: %s", err)
+	}
+	defer hs.Close()
+	expect := merkletree.NewMerkleStorage(0, nil, hs)
+	store := &failCommitStore{PersistStorage: memdb.NewStore()}
+	defer store.Close()
+	bs := NewBlockStore(store)
+	if err := bs.InitBlockMerkleTree(filepath.Join(dir, "block.hs")); err != nil {
+		t.Fatalf("init block merkle tree: %s", err)
+	}
+	defer bs.Close()
+	var prev common.Uint256
+	for h := uint32(0); h < 5; h++ {
+		blk := testMerkleBlock(t, h, prev)
+		store.fail = h%2 == 1
+		if store.fail {
+			if err := bs.SaveBlock(blk); err != errCommit {
+				t.Errorf("save block %d with failed commit: %v", h, err)
+			}
+			if bs.BlockMerkleTree().LeafSize() != uint64(h) {
+				t.Errorf("tree changed by failed commit: %d", bs.BlockMerkleTree().LeafSize())
+			}
+		}
+		if err := bs.SaveBlock(blk); err != nil {
+			t.Errorf("save block %d: %s", h, err)
+		}
+		expect.AddLeaf(blk.Header.TransactionsRoot)
+		prev = blk.Hash()
+	}
+	if bs.BlockMerkleTree().Root() != expect.Root() {
+		t.Errorf("block merkle tree after retry:\n%X\n%X", bs.BlockMerkleTree().Root(), expect.Root())
+	}
+	for m := uint64(0); m < 5; m++ {
+		proof, err := bs.BlockMerkleTree().InclusionProof(m, 5)
+		expectProof, _ := expect.InclusionProof(m, 5)
+		if err != nil || !reflect.DeepEqual(proof, expectProof) {
+			t.Errorf("inclusion proof of %d: %v, %v", m, proof, err)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/core/states"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/storage"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+var (
+	// ErrIssuerNotAuthorized issuer of bookkeeper transaction is not a current bookkeeper
+	ErrIssuerNotAuthorized = errors.New("issuer is not a current bookkeeper")
+	// ErrBookkeeperCert cert of bookkeeper transaction is not signed by issuer
+	ErrBookkeeperCert = errors.New("invalid bookkeeper cert")
+	// ErrBookkeeperExist bookkeeper to be added is already in the list
+	ErrBookkeeperExist = errors.New("bookkeeper already exists")
+	// ErrBookkeeperNotExist bookkeeper to be removed is not in the list
+	ErrBookkeeperNotExist = errors.New("bookkeeper does not exist")
+	// ErrNoBookkeeper the last bookkeeper can not be removed
+	ErrNoBookkeeper = errors.New("bookkeeper list can not be empty")
+	// ErrBookkeeperAction unknown bookkeeper action
+	ErrBookkeeperAction = errors.New("unknown bookkeeper action")
+	// ErrBookkeeperHeight bookkeeper transaction is not issued for the block
+	ErrBookkeeperHeight = errors.New("bookkeeper transaction of other height")
+	// ErrBookkeeperThreshold bookkeeper change is not certified by enough current bookkeepers
+	ErrBookkeeperThreshold = errors.New("bookkeeper change under threshold")
+)
+
+// BookkeeperStore apply Bookkeeper transactions to the bookkeeper list,
+// and persist it into PersistStorage with key schema below:
+// ST_BOOKKEEPER + block height  => bookkeeper state since the height
+//
+// the state is saved only at the heights when the list changes. the changes
+// made by the block at height h take effect from height h+1, thus the state
+// saved at h has CurrBookkeeper of h and NextBookkeeper of h+1
+type BookkeeperStore struct {
+	store   storage.PersistStorage
+	elector Elector
+}
+
+// Elector elect the bookkeepers of next epoch at the block of epoch boundary, i.e. VoteStore
+type Elector interface {
+	ElectNextBookkeepers(height uint32) ([]keypair.PublicKey, common.Address, error)
+}
+
+// NewBookkeeperStore create an new bookkeeper store on the persist storage
+func NewBookkeeperStore(store storage.PersistStorage) *BookkeeperStore {
+	return &BookkeeperStore{
+		store: store,
+	}
+}
+
+// SetElector set the elector whose result replaces the bookkeeper list at epoch boundary.
+// the votes of the block should be applied to the elector before the block is applied here
+func (bs *BookkeeperStore) SetElector(elector Elector) {
+	bs.elector = elector
+}
+
+// InitBookkeepers set the bookkeepers of genesis block
+func (bs *BookkeeperStore) InitBookkeepers(bookkeepers []keypair.PublicKey) error {
+	return bs.saveState(0, &states.BookkeeperState{
+		CurrBookkeeper: bookkeepers,
+		NextBookkeeper: bookkeepers,
+	})
+}
+
+// GetBookkeeperState get the bookkeeper state at height
+// storage.ErrNotFound returned if bookkeepers have not been initialized
+func (bs *BookkeeperStore) GetBookkeeperState(height uint32) (*states.BookkeeperState, error) {
+	it := bs.store.NewIterator([]byte{byte(storage.ST_BOOKKEEPER)})
+	defer it.Release()
+
+	key := bookkeeperKey(height)
+	var ok bool
+	if it.Seek(key) {
+		ok = bytes.Equal(it.Key(), key) || it.Prev()
+	} else {
+		ok = it.Last()
+	}
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	state := new(states.BookkeeperState)
+	if err := state.Deserialize(bytes.NewReader(it.Value())); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(it.Key(), key) {
+		// no change since the saved height
+		state.CurrBookkeeper = state.NextBookkeeper
+	}
+	return state, nil
+}
+
+// GetBookkeepers get the bookkeepers who sign the block at height
+func (bs *BookkeeperStore) GetBookkeepers(height uint32) ([]keypair.PublicKey, error) {
+	state, err := bs.GetBookkeeperState(height)
+	if err != nil {
+		return nil, err
+	}
+	return state.CurrBookkeeper, nil
+}
+
+// ApplyBlock apply the Bookkeeper transactions in block to the bookkeeper list.
+// every change, i.e. the same action on the same public key, takes effect only if it is
+// certified by block.BookkeeperThreshold of the current bookkeepers, each in one transaction.
+// at epoch boundary, the bookkeepers elected by the elector if any replace the list.
+// nothing is saved if any transaction or change is invalid. the block can be applied again,
+// i.e. after failure of other stores, the same state is saved
+func (bs *BookkeeperStore) ApplyBlock(blk *block.Block) error {
+	height := blk.Header.Height
+	if height == 0 {
+		return bs.applyGenesis(blk)
+	}
+	var chainID uint32
+	if blk.Header.HasChainID() {
+		chainID = blk.Header.ChainID
+	}
+	state, err := bs.GetBookkeeperState(height)
+	if err != nil {
+		return err
+	}
+	// CurrBookkeeper is the NextBookkeeper before the block, even if the block has been applied
+	current := state.CurrBookkeeper
+	var changes []*bookkeeperChange
+	for _, tx := range blk.Transactions {
+		if tx.TxType != transaction.Bookkeeper {
+			continue
+		}
+		bk, ok := tx.Payload.(*payload.Bookkeeper)
+		if !ok {
+			return ErrBookkeeperAction
+		}
+		if bk.Height != height {
+			return ErrBookkeeperHeight
+		}
+		if err := verifyBookkeeper(current, bk, chainID); err != nil {
+			return err
+		}
+		changes = addBookkeeperCert(changes, bk)
+	}
+	var elected []keypair.PublicKey
+	if bs.elector != nil {
+		if elected, _, err = bs.elector.ElectNextBookkeepers(height); err != nil {
+			return err
+		}
+	}
+	if len(changes) == 0 && len(elected) == 0 {
+		return nil
+	}
+	threshold := block.BookkeeperThreshold(len(current))
+	next := append([]keypair.PublicKey{}, current...)
+	for _, change := range changes {
+		if len(change.issuers) < threshold {
+			return ErrBookkeeperThreshold
+		}
+		if next, err = change.apply(next); err != nil {
+			return err
+		}
+	}
+	if len(elected) > 0 {
+		next = elected
+	}
+	return bs.saveState(height, &states.BookkeeperState{
+		CurrBookkeeper: current,
+		NextBookkeeper: next,
+	})
+}
+
+// applyGenesis init the bookkeeper list with the bookkeepers who add themselves in genesis block.
+// these transactions have no cert, the genesis block must be checked by validation.ValidateGenesisBlock
+func (bs *BookkeeperStore) applyGenesis(blk *block.Block) error {
+	var bookkeepers []keypair.PublicKey
+	for _, tx := range blk.Transactions {
+		if tx.TxType != transaction.Bookkeeper {
+			continue
+		}
+		bk, ok := tx.Payload.(*payload.Bookkeeper)
+		if !ok || bk.Action != payload.BookkeeperActionADD {
+			return ErrBookkeeperAction
+		}
+		if bk.Height != 0 {
+			return ErrBookkeeperHeight
+		}
+		if !keypair.ComparePublicKey(bk.Issuer, bk.PubKey) {
+			return ErrIssuerNotAuthorized
+		}
+		if indexOfPubKey(bookkeepers, bk.PubKey) >= 0 {
+			return ErrBookkeeperExist
+		}
+		bookkeepers = append(bookkeepers, bk.PubKey)
+	}
+	if len(bookkeepers) == 0 {
+		return nil
+	}
+	return bs.InitBookkeepers(bookkeepers)
+}
+
+// bookkeeperChange the action on public key with the distinct issuers certifying it
+type bookkeeperChange struct {
+	action  payload.BookkeeperAction
+	pubKey  keypair.PublicKey
+	issuers []keypair.PublicKey
+}
+
+// addBookkeeperCert add the issuer of bk to its change, the changes are kept in order of first appearance
+func addBookkeeperCert(changes []*bookkeeperChange, bk *payload.Bookkeeper) []*bookkeeperChange {
+	var change *bookkeeperChange
+	for _, c := range changes {
+		if c.action == bk.Action && keypair.ComparePublicKey(c.pubKey, bk.PubKey) {
+			change = c
+			break
+		}
+	}
+	if change == nil {
+		change = &bookkeeperChange{action: bk.Action, pubKey: bk.PubKey}
+		changes = append(changes, change)
+	}
+	if indexOfPubKey(change.issuers, bk.Issuer) < 0 {
+		change.issuers = append(change.issuers, bk.Issuer)
+	}
+	return changes
+}
+
+// verifyBookkeeper verify the Bookkeeper payload is certified by one of current
+func verifyBookkeeper(current []keypair.PublicKey, bk *payload.Bookkeeper, chainID uint32) error {
+	if indexOfPubKey(current, bk.Issuer) < 0 {
+		return ErrIssuerNotAuthorized
+	}
+	if err := signature.Verify(bk.Issuer, bk.CertData(chainID), bk.Cert); err != nil {
+		return ErrBookkeeperCert
+	}
+	if bk.Action != payload.BookkeeperActionADD && bk.Action != payload.BookkeeperActionSUB {
+		return ErrBookkeeperAction
+	}
+	return nil
+}
+
+// apply the change to next
+func (change *bookkeeperChange) apply(next []keypair.PublicKey) ([]keypair.PublicKey, error) {
+	i := indexOfPubKey(next, change.pubKey)
+	if change.action == payload.BookkeeperActionADD {
+		if i >= 0 {
+			return nil, ErrBookkeeperExist
+		}
+		return append(next, change.pubKey), nil
+	}
+	if i < 0 {
+		return nil, ErrBookkeeperNotExist
+	}
+	if len(next) == 1 {
+		return nil, ErrNoBookkeeper
+	}
+	return append(next[:i], next[i+1:]...), nil
+}
+
+// saveState write the state at height atomically in batch
+func (bs *BookkeeperStore) saveState(height uint32, state *states.BookkeeperState) error {
+	buf := new(bytes.Buffer)
+	if err := state.Serialize(buf); err != nil {
+		return err
+	}
+	bs.store.NewBatch()
+	bs.store.BatchPut(bookkeeperKey(height), buf.Bytes())
+	return bs.store.BatchCommit()
+}
+
+func indexOfPubKey(pubKeys []keypair.PublicKey, pubKey keypair.PublicKey) int {
+	for i, pk := range pubKeys {
+		if keypair.ComparePublicKey(pk, pubKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// height is encoded with big endian to keep the keys in order
+func bookkeeperKey(height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(storage.ST_BOOKKEEPER)
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
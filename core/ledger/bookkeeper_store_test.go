@@ -0,0 +1,165 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/core/signature/signaturetest"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/memdb"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func newTestBookkeeperStore(t *testing.T) (*BookkeeperStore, func()) {
+	store := memdb.NewStore()
+	return NewBookkeeperStore(store), func() {
+		store.Close()
+	}
+}
+
+func newBookkeeperTx(t *testing.T, issuer *signaturetest.Signatory, pk keypair.PublicKey, action payload.BookkeeperAction, height uint32) *transaction.Transaction {
+	bk := &payload.Bookkeeper{
+		PubKey: pk,
+		Action: action,
+		Height: height,
+		Issuer: issuer.PublicKey(),
+	}
+	cert, err := signature.Sign(issuer, bk.CertData(0))
+	if err != nil {
+		t.Fatalf("sign cert: %s", err)
+	}
+	bk.Cert = cert
+	return &transaction.Transaction{Version: transaction.TxVersion, TxType: transaction.Bookkeeper, Payload: bk}
+}
+
+func TestBookkeeperStoreApplyBlock(t *testing.T) {
+	bs, closer := newTestBookkeeperStore(t)
+	defer closer()
+
+	if _, err := bs.GetBookkeepers(0); err != storage.ErrNotFound {
+		t.Errorf("bookkeepers before init: %v", err)
+	}
+	admin := signaturetest.NewSignatory(t)
+	if err := bs.InitBookkeepers([]keypair.PublicKey{admin.PublicKey()}); err != nil {
+		t.Fatalf("init bookkeepers: %s", err)
+	}
+
+	other := signaturetest.NewSignatory(t)
+	blk := &block.Block{
+		Header:       &block.Header{Height: 3},
+		Transactions: []*transaction.Transaction{newBookkeeperTx(t, admin, other.PublicKey(), payload.BookkeeperActionADD, 3)},
+	}
+	if err := bs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	if err := bs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block again: %s", err)
+	}
+	for height, n := range map[uint32]int{0: 1, 2: 1, 3: 1, 4: 2, 100: 2} {
+		bks, err := bs.GetBookkeepers(height)
+		if err != nil || len(bks) != n {
+			t.Errorf("bookkeepers at %d: %d, %v", height, len(bks), err)
+		}
+	}
+	state, err := bs.GetBookkeeperState(3)
+	if err != nil || len(state.NextBookkeeper) != 2 {
+		t.Errorf("bookkeeper state at 3: %v", err)
+	}
+
+	blk = &block.Block{
+		Header: &block.Header{Height: 5},
+		Transactions: []*transaction.Transaction{
+			newBookkeeperTx(t, admin, admin.PublicKey(), payload.BookkeeperActionSUB, 5),
+			newBookkeeperTx(t, other, admin.PublicKey(), payload.BookkeeperActionSUB, 5),
+		},
+	}
+	if err := bs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	bks, err := bs.GetBookkeepers(6)
+	if err != nil || len(bks) != 1 || !keypair.ComparePublicKey(bks[0], other.PublicKey()) {
+		t.Errorf("bookkeepers at 6: %v", err)
+	}
+}
+
+func TestBookkeeperStoreInvalidTx(t *testing.T) {
+	bs, closer := newTestBookkeeperStore(t)
+	defer closer()
+
+	admin := signaturetest.NewSignatory(t)
+	if err := bs.InitBookkeepers([]keypair.PublicKey{admin.PublicKey()}); err != nil {
+		t.Fatalf("init bookkeepers: %s", err)
+	}
+	other := signaturetest.NewSignatory(t)
+	forged := newBookkeeperTx(t, admin, other.PublicKey(), payload.BookkeeperActionADD, 1)
+	forged.Payload.(*payload.Bookkeeper).Action = payload.BookkeeperActionSUB
+	// cert of height 2 replayed at height 1
+	replayed := newBookkeeperTx(t, admin, other.PublicKey(), payload.BookkeeperActionADD, 2)
+	replayed.Payload.(*payload.Bookkeeper).Height = 1
+	cases := []struct {
+		tx  *transaction.Transaction
+		err error
+	}{
+		{newBookkeeperTx(t, other, other.PublicKey(), payload.BookkeeperActionADD, 1), ErrIssuerNotAuthorized},
+		{forged, ErrBookkeeperCert},
+		{replayed, ErrBookkeeperCert},
+		{newBookkeeperTx(t, admin, other.PublicKey(), payload.BookkeeperActionADD, 2), ErrBookkeeperHeight},
+		{newBookkeeperTx(t, admin, admin.PublicKey(), payload.BookkeeperActionADD, 1), ErrBookkeeperExist},
+		{newBookkeeperTx(t, admin, other.PublicKey(), payload.BookkeeperActionSUB, 1), ErrBookkeeperNotExist},
+		{newBookkeeperTx(t, admin, admin.PublicKey(), payload.BookkeeperActionSUB, 1), ErrNoBookkeeper},
+	}
+	for i, c := range cases {
+		blk := &block.Block{
+			Header:       &block.Header{Height: 1},
+			Transactions: []*transaction.Transaction{c.tx},
+		}
+		if err := bs.ApplyBlock(blk); err != c.err {
+			t.Errorf("apply invalid block %d: %v", i, err)
+		}
+	}
+	if bks, err := bs.GetBookkeepers(2); err != nil || len(bks) != 1 {
+		t.Errorf("bookkeepers after invalid blocks: %v", err)
+	}
+}
+
+func TestBookkeeperStoreThreshold(t *testing.T) {
+	bs, closer := newTestBookkeeperStore(t)
+	defer closer()
+
+	signers, pks := signaturetest.NewSignatories(t, 4)
+	if err := bs.InitBookkeepers(pks); err != nil {
+		t.Fatalf("init bookkeepers: %s", err)
+	}
+	candidate := signaturetest.NewSignatory(t).PublicKey()
+	add := func(issuers ...*signaturetest.Signatory) *block.Block {
+		blk := &block.Block{Header: &block.Header{Height: 1}}
+		for _, issuer := range issuers {
+			tx := newBookkeeperTx(t, issuer, candidate, payload.BookkeeperActionADD, 1)
+			blk.Transactions = append(blk.Transactions, tx)
+		}
+		return blk
+	}
+	// 3 of 4 bookkeepers are required
+	rejected := []*block.Block{
+		add(signers[0]),
+		add(signers[0], signers[0], signers[0]),
+		add(signers[0], signers[1]),
+	}
+	for i, blk := range rejected {
+		if err := bs.ApplyBlock(blk); err != ErrBookkeeperThreshold {
+			t.Errorf("apply block %d under threshold: %v", i, err)
+		}
+	}
+	if bks, err := bs.GetBookkeepers(2); err != nil || len(bks) != 4 {
+		t.Errorf("bookkeepers after rejected blocks: %d, %v", len(bks), err)
+	}
+	if err := bs.ApplyBlock(add(signers[0], signers[1], signers[2])); err != nil {
+		t.Fatalf("apply block certified by threshold: %s", err)
+	}
+	if bks, err := bs.GetBookkeepers(2); err != nil || len(bks) != 5 {
+		t.Errorf("bookkeepers after change: %d, %v", len(bks), err)
+	}
+}
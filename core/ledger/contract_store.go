@@ -0,0 +1,163 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/memstore"
+)
+
+var (
+	// ErrContractExist contract of the same address has been deployed
+	ErrContractExist = errors.New("contract already exists")
+	// ErrContractNoStorage contract is deployed without NeedStorage
+	ErrContractNoStorage = errors.New("contract does not need storage")
+	// ErrContractValue the staged value is not raw bytes
+	ErrContractValue = errors.New("invalid contract store value")
+)
+
+// ContractStore persist deployed contracts and their storage with key schema below:
+// ST_CONTRACT + contract address       => deploy code
+// ST_STORAGE + contract address + key  => storage value
+//
+// the changes are staged in memory, and persisted with the block by ApplyBlock
+// atomically, or thrown away by Discard, i.e. when the block fails
+type ContractStore struct {
+	store storage.PersistStorage
+	cache *memstore.CacheStore
+}
+
+// NewContractStore create an new contract store on the persist storage
+func NewContractStore(store storage.PersistStorage) *ContractStore {
+	return &ContractStore{
+		store: store,
+		cache: memstore.NewCacheStore(store),
+	}
+}
+
+// ApplyBlock deploy the contracts of Deploy transactions in block, and persist
+// them with the staged changes atomically. ErrContractExist returned if the contract
+// has been deployed, even in the same block. nothing is saved if any error occurs
+func (cs *ContractStore) ApplyBlock(blk *block.Block) error {
+	for _, tx := range blk.Transactions {
+		if tx.TxType != transaction.Deploy {
+			continue
+		}
+		dc, ok := tx.Payload.(*payload.DeployCode)
+		if !ok {
+			continue
+		}
+		if _, err := cs.DeployContract(dc); err != nil {
+			cs.cache.Discard()
+			return err
+		}
+	}
+	if err := cs.cache.Commit(); err != nil {
+		cs.cache.Discard()
+		return err
+	}
+	return nil
+}
+
+// Discard throw away the changes staged since the last block
+func (cs *ContractStore) Discard() {
+	cs.cache.Discard()
+}
+
+// DeployContract stage the contract under the address of its code
+func (cs *ContractStore) DeployContract(dc *payload.DeployCode) (common.Address, error) {
+	addr := dc.Code.Address()
+	item, err := cs.cache.TryGet(byte(storage.ST_CONTRACT), addr[:])
+	if err != nil {
+		return common.ADDRESS_EMPTY, err
+	}
+	if item != nil {
+		return common.ADDRESS_EMPTY, ErrContractExist
+	}
+	buf := new(bytes.Buffer)
+	if err := dc.Serialize(buf); err != nil {
+		return common.ADDRESS_EMPTY, err
+	}
+	cs.cache.Put(byte(storage.ST_CONTRACT), addr[:], &memstore.RawValue{Bytes: buf.Bytes()}, storage.Changed)
+	return addr, nil
+}
+
+// GetContract get the deployed contract by address, including the staged one
+func (cs *ContractStore) GetContract(addr common.Address) (*payload.DeployCode, error) {
+	data, err := cs.get(byte(storage.ST_CONTRACT), addr[:])
+	if err != nil {
+		return nil, err
+	}
+	dc := new(payload.DeployCode)
+	if err := dc.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// GetStorage get the value of key in contract storage, including the staged changes
+func (cs *ContractStore) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	if err := cs.checkStorage(addr); err != nil {
+		return nil, err
+	}
+	return cs.get(byte(storage.ST_STORAGE), storageKey(addr, key))
+}
+
+// PutStorage stage the key-value pair into contract storage
+func (cs *ContractStore) PutStorage(addr common.Address, key, value []byte) error {
+	if err := cs.checkStorage(addr); err != nil {
+		return err
+	}
+	rv := &memstore.RawValue{Bytes: append([]byte(nil), value...)}
+	cs.cache.Put(byte(storage.ST_STORAGE), storageKey(addr, key), rv, storage.Changed)
+	return nil
+}
+
+// DeleteStorage stage the deletion of key in contract storage
+func (cs *ContractStore) DeleteStorage(addr common.Address, key []byte) error {
+	if err := cs.checkStorage(addr); err != nil {
+		return err
+	}
+	cs.cache.Delete(byte(storage.ST_STORAGE), storageKey(addr, key))
+	return nil
+}
+
+// checkStorage check whether the contract is deployed with NeedStorage
+func (cs *ContractStore) checkStorage(addr common.Address) error {
+	dc, err := cs.GetContract(addr)
+	if err != nil {
+		return err
+	}
+	if !dc.NeedStorage {
+		return ErrContractNoStorage
+	}
+	return nil
+}
+
+// get the value from cache, storage.ErrNotFound returned if not found or deleted
+func (cs *ContractStore) get(prefix byte, key []byte) ([]byte, error) {
+	item, err := cs.cache.TryGet(prefix, key)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, storage.ErrNotFound
+	}
+	rv, ok := item.Value.(*memstore.RawValue)
+	if !ok {
+		return nil, ErrContractValue
+	}
+	return rv.Bytes, nil
+}
+
+// storageKey the key of contract storage without ST_STORAGE prefix
+func storageKey(addr common.Address, key []byte) []byte {
+	k := make([]byte, 0, common.ADDR_LEN+len(key))
+	k = append(k, addr[:]...)
+	return append(k, key...)
+}
@@ -0,0 +1,198 @@
+package ledger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/smartcontract/types"
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/memdb"
+)
+
+func newTestContractStore(t *testing.T) (*ContractStore, func()) {
+	store := memdb.NewStore()
+	return NewContractStore(store), func() {
+		store.Close()
+	}
+}
+
+func testDeployTx(t *testing.T, code types.VMCode, name string) *transaction.Transaction {
+	tx, err := transaction.NewDeployTx(code, name, "1.0", "author", "email", "desc", true)
+	if err != nil {
+		t.Fatalf("new deploy tx: %s", err)
+	}
+	return tx
+}
+
+func TestContractStoreDeploy(t *testing.T) {
+	cs, closer := newTestContractStore(t)
+	defer closer()
+
+	code := types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}
+	blk := &block.Block{
+		Header: &block.Header{Height: 1},
+		Transactions: []*transaction.Transaction{
+			testDeployTx(t, code, "name"),
+		},
+	}
+	if err := cs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	dc, err := cs.GetContract(code.Address())
+	if err != nil || dc.Name != "name" || !dc.NeedStorage {
+		t.Errorf("get contract: %v", err)
+	}
+	// redeploy is rejected
+	redeploy := &block.Block{
+		Header: &block.Header{Height: 2},
+		Transactions: []*transaction.Transaction{
+			testDeployTx(t, code, "other"),
+		},
+	}
+	if err := cs.ApplyBlock(redeploy); err != ErrContractExist {
+		t.Errorf("redeploy contract: %v", err)
+	}
+	if dc, err := cs.GetContract(code.Address()); err != nil || dc.Name != "name" {
+		t.Errorf("redeployed contract: %v", err)
+	}
+
+	other := types.VMCode{VMType: types.NEOVM, Code: []byte{0xFE}}
+	dup := &block.Block{
+		Header: &block.Header{Height: 3},
+		Transactions: []*transaction.Transaction{
+			testDeployTx(t, other, "a"),
+			testDeployTx(t, other, "b"),
+		},
+	}
+	if err := cs.ApplyBlock(dup); err != ErrContractExist {
+		t.Errorf("deploy the same contract in block: %v", err)
+	}
+	if _, err := cs.GetContract(other.Address()); err != storage.ErrNotFound {
+		t.Errorf("contract of rejected block: %v", err)
+	}
+
+	third := types.VMCode{VMType: types.NEOVM, Code: []byte{0xFD}}
+	addr, err := cs.DeployContract(&payload.DeployCode{Code: third})
+	if err != nil || addr != third.Address() {
+		t.Errorf("deploy contract: %v", err)
+	}
+	if _, err := cs.DeployContract(&payload.DeployCode{Code: third}); err != ErrContractExist {
+		t.Errorf("redeploy contract: %v", err)
+	}
+	cs.Discard()
+	if _, err := cs.GetContract(third.Address()); err != storage.ErrNotFound {
+		t.Errorf("discarded contract: %v", err)
+	}
+}
+
+func TestContractStoreStorage(t *testing.T) {
+	cs, closer := newTestContractStore(t)
+	defer closer()
+
+	withStorage, err := cs.DeployContract(&payload.DeployCode{
+		Code:        types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}},
+		NeedStorage: true,
+	})
+	if err != nil {
+		t.Fatalf("deploy contract: %s", err)
+	}
+	withoutStorage, err := cs.DeployContract(&payload.DeployCode{
+		Code: types.VMCode{VMType: types.NEOVM, Code: []byte{0xFE}},
+	})
+	if err != nil {
+		t.Fatalf("deploy contract: %s", err)
+	}
+
+	if err := cs.PutStorage(withStorage, []byte("k"), []byte("v")); err != nil {
+		t.Errorf("put storage: %s", err)
+	}
+	if v, err := cs.GetStorage(withStorage, []byte("k")); err != nil || !bytes.Equal(v, []byte("v")) {
+		t.Errorf("get storage: %v", err)
+	}
+	if err := cs.DeleteStorage(withStorage, []byte("k")); err != nil {
+		t.Errorf("delete storage: %s", err)
+	}
+	if _, err := cs.GetStorage(withStorage, []byte("k")); err != storage.ErrNotFound {
+		t.Errorf("get deleted storage: %v", err)
+	}
+
+	if err := cs.PutStorage(withoutStorage, []byte("k"), []byte("v")); err != ErrContractNoStorage {
+		t.Errorf("put storage of contract without storage: %v", err)
+	}
+	if _, err := cs.GetStorage(withoutStorage, []byte("k")); err != ErrContractNoStorage {
+		t.Errorf("get storage of contract without storage: %v", err)
+	}
+	var unknown = withStorage
+	unknown[1] ^= 0xFF
+	if err := cs.PutStorage(unknown, []byte("k"), []byte("v")); err != storage.ErrNotFound {
+		t.Errorf("put storage of unknown contract: %v", err)
+	}
+}
+
+func TestContractStoreStaging(t *testing.T) {
+	store := &failCommitStore{PersistStorage: memdb.NewStore()}
+	defer store.Close()
+	cs := NewContractStore(store)
+
+	code := types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}
+	addr, err := cs.DeployContract(&payload.DeployCode{Code: code, NeedStorage: true})
+	if err != nil {
+		t.Fatalf("deploy contract: %s", err)
+	}
+	if err := cs.PutStorage(addr, []byte("k"), []byte("v")); err != nil {
+		t.Fatalf("put storage: %s", err)
+	}
+	if _, err := NewContractStore(store).GetContract(addr); err != storage.ErrNotFound {
+		t.Errorf("staged contract is persisted: %v", err)
+	}
+
+	// the staged changes are reverted if the block fails
+	store.fail = true
+	blk := &block.Block{Header: &block.Header{Height: 1}}
+	if err := cs.ApplyBlock(blk); err != errCommit {
+		t.Errorf("apply block with failed commit: %v", err)
+	}
+	if _, err := cs.GetContract(addr); err != storage.ErrNotFound {
+		t.Errorf("contract of failed block: %v", err)
+	}
+
+	if _, err := cs.DeployContract(&payload.DeployCode{Code: code, NeedStorage: true}); err != nil {
+		t.Fatalf("deploy contract: %s", err)
+	}
+	if err := cs.PutStorage(addr, []byte("k"), []byte("v")); err != nil {
+		t.Fatalf("put storage: %s", err)
+	}
+	if err := cs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	persisted := NewContractStore(store)
+	if v, err := persisted.GetStorage(addr, []byte("k")); err != nil || !bytes.Equal(v, []byte("v")) {
+		t.Errorf("storage of applied block: %v", err)
+	}
+
+	if err := cs.DeleteStorage(addr, []byte("k")); err != nil {
+		t.Fatalf("delete storage: %s", err)
+	}
+	if _, err := persisted.GetStorage(addr, []byte("k")); err != nil {
+		t.Errorf("staged deletion is persisted: %v", err)
+	}
+	cs.Discard()
+	if v, err := cs.GetStorage(addr, []byte("k")); err != nil || !bytes.Equal(v, []byte("v")) {
+		t.Errorf("storage after discard: %v", err)
+	}
+}
+
+func TestContractStoreInvalidValue(t *testing.T) {
+	cs, closer := newTestContractStore(t)
+	defer closer()
+
+	code := types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}
+	addr := code.Address()
+	cs.cache.Put(byte(storage.ST_CONTRACT), addr[:], &payload.DeployCode{}, storage.Changed)
+	if _, err := cs.GetContract(addr); err != ErrContractValue {
+		t.Errorf("get contract of invalid value: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/states"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/storage"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+var (
+	// ErrVoteWeightOverflow total weight of candidate exceeds uint64
+	ErrVoteWeightOverflow = errors.New("vote weight overflow")
+)
+
+// WeightSource get the vote weight of account, i.e. balance
+type WeightSource interface {
+	Weight(account common.Address) (uint64, error)
+}
+
+// CandidateWeight the total weight voted for candidate
+type CandidateWeight struct {
+	PubKey keypair.PublicKey
+	Weight uint64
+}
+
+// VoteStore apply Vote transactions and elect bookkeepers by votes,
+// the latest vote of every account is persisted with key schema below:
+// ST_VOTE + account  => vote state
+type VoteStore struct {
+	store   storage.PersistStorage
+	weights WeightSource
+	epoch   uint32
+	k       int
+}
+
+// NewVoteStore create an new vote store on the persist storage
+// the top k candidates are elected as bookkeepers every epoch blocks
+func NewVoteStore(store storage.PersistStorage, weights WeightSource, epoch uint32, k int) *VoteStore {
+	return &VoteStore{
+		store:   store,
+		weights: weights,
+		epoch:   epoch,
+		k:       k,
+	}
+}
+
+// ApplyBlock save the votes in block atomically, the latest vote replaces the previous one.
+// the vote of an account is revoked by a vote without public key
+func (vs *VoteStore) ApplyBlock(blk *block.Block) error {
+	var votes []*payload.Vote
+	for _, tx := range blk.Transactions {
+		if tx.TxType != transaction.Vote {
+			continue
+		}
+		if vote, ok := tx.Payload.(*payload.Vote); ok {
+			votes = append(votes, vote)
+		}
+	}
+	if len(votes) == 0 {
+		return nil
+	}
+
+	values := make([][]byte, len(votes))
+	for i, vote := range votes {
+		if len(vote.PubKeys) == 0 {
+			continue
+		}
+		buf := new(bytes.Buffer)
+		state := &states.VoteState{PubKeys: vote.PubKeys}
+		if err := state.Serialize(buf); err != nil {
+			return err
+		}
+		values[i] = buf.Bytes()
+	}
+	vs.store.NewBatch()
+	for i, vote := range votes {
+		if values[i] == nil {
+			vs.store.BatchDelete(voteKey(vote.Account))
+		} else {
+			vs.store.BatchPut(voteKey(vote.Account), values[i])
+		}
+	}
+	return vs.store.BatchCommit()
+}
+
+// GetVote get the latest vote of account
+func (vs *VoteStore) GetVote(account common.Address) (*states.VoteState, error) {
+	data, err := vs.store.Get(voteKey(account))
+	if err != nil {
+		return nil, err
+	}
+	state := new(states.VoteState)
+	if err := state.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Tally sum up the weight of every candidate,
+// each candidate voted by an account gets the full weight of the account.
+// ErrVoteWeightOverflow returned if the total weight of any candidate overflows.
+// the candidates are sorted by weight descending, then by public key ascending
+func (vs *VoteStore) Tally() ([]*CandidateWeight, error) {
+	it := vs.store.NewIterator([]byte{byte(storage.ST_VOTE)})
+	defer it.Release()
+
+	candidates := make(map[string]*CandidateWeight)
+	for it.Next() {
+		var account common.Address
+		if err := account.FromBytes(it.Key()[1:]); err != nil {
+			return nil, err
+		}
+		var state states.VoteState
+		if err := state.Deserialize(bytes.NewReader(it.Value())); err != nil {
+			return nil, err
+		}
+		weight, err := vs.weights.Weight(account)
+		if err != nil {
+			return nil, err
+		}
+		voted := make(map[string]bool, len(state.PubKeys))
+		for _, pk := range state.PubKeys {
+			key := string(keypair.SerializePublicKey(pk))
+			if voted[key] {
+				continue
+			}
+			voted[key] = true
+			c, ok := candidates[key]
+			if !ok {
+				c = &CandidateWeight{PubKey: pk}
+				candidates[key] = c
+			}
+			if c.Weight > math.MaxUint64-weight {
+				return nil, ErrVoteWeightOverflow
+			}
+			c.Weight += weight
+		}
+	}
+
+	keys := make([]string, 0, len(candidates))
+	for key := range candidates {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		wi, wj := candidates[keys[i]].Weight, candidates[keys[j]].Weight
+		if wi != wj {
+			return wi > wj
+		}
+		return keys[i] < keys[j]
+	})
+	result := make([]*CandidateWeight, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, candidates[key])
+	}
+	return result, nil
+}
+
+// Elect get the top k candidates with non-zero weight
+func (vs *VoteStore) Elect() ([]keypair.PublicKey, error) {
+	candidates, err := vs.Tally()
+	if err != nil {
+		return nil, err
+	}
+	elected := make([]keypair.PublicKey, 0, vs.k)
+	for _, c := range candidates {
+		if len(elected) >= vs.k || c.Weight == 0 {
+			break
+		}
+		elected = append(elected, c.PubKey)
+	}
+	return elected, nil
+}
+
+// IsEpochBoundary check whether the block at height is the last block of epoch
+func (vs *VoteStore) IsEpochBoundary(height uint32) bool {
+	return vs.epoch > 0 && (height+1)%vs.epoch == 0
+}
+
+// ElectNextBookkeepers elect the bookkeepers of next epoch if the block at height is at epoch boundary,
+// and get their address to be set as Header.NextBookkeeper of the block. the elected sign
+// the blocks of next epoch, which are nil if not at epoch boundary or no one is voted
+func (vs *VoteStore) ElectNextBookkeepers(height uint32) ([]keypair.PublicKey, common.Address, error) {
+	if !vs.IsEpochBoundary(height) {
+		return nil, common.ADDRESS_EMPTY, nil
+	}
+	elected, err := vs.Elect()
+	if err != nil || len(elected) == 0 {
+		return nil, common.ADDRESS_EMPTY, err
+	}
+	addr, err := block.AddressFromBookkeepers(elected)
+	if err != nil {
+		return nil, common.ADDRESS_EMPTY, err
+	}
+	return elected, addr, nil
+}
+
+func voteKey(account common.Address) []byte {
+	return append([]byte{byte(storage.ST_VOTE)}, account[:]...)
+}
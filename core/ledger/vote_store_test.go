@@ -0,0 +1,150 @@
+package ledger
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature/signaturetest"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/memdb"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+type testWeights map[common.Address]uint64
+
+func (tw testWeights) Weight(account common.Address) (uint64, error) {
+	return tw[account], nil
+}
+
+func newVoteTx(account common.Address, pks ...keypair.PublicKey) *transaction.Transaction {
+	return &transaction.Transaction{
+		TxType:  transaction.Vote,
+		Payer:   account,
+		Payload: &payload.Vote{PubKeys: pks, Account: account},
+	}
+}
+
+func TestVoteStoreElect(t *testing.T) {
+	store := memdb.NewStore()
+	defer store.Close()
+
+	var pks []keypair.PublicKey
+	for i := 0; i < 4; i++ {
+		_, pk, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+		pks = append(pks, pk)
+	}
+	alice, bob, carol := common.Address{1}, common.Address{2}, common.Address{3}
+	weights := testWeights{alice: 10, bob: 5, carol: 0}
+	vs := NewVoteStore(store, weights, 10, 2)
+
+	blk := &block.Block{
+		Header: &block.Header{Height: 1},
+		Transactions: []*transaction.Transaction{
+			newVoteTx(alice, pks[0], pks[1]),
+			newVoteTx(bob, pks[1], pks[2], pks[2]),
+			newVoteTx(carol, pks[3]),
+		},
+	}
+	if err := vs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	candidates, err := vs.Tally()
+	if err != nil || len(candidates) != 4 {
+		t.Fatalf("tally: %v", err)
+	}
+	if !keypair.ComparePublicKey(candidates[0].PubKey, pks[1]) || candidates[0].Weight != 15 {
+		t.Errorf("tally top candidate: %d", candidates[0].Weight)
+	}
+	if candidates[2].Weight != 5 || candidates[3].Weight != 0 {
+		t.Errorf("tally: %d, %d", candidates[2].Weight, candidates[3].Weight)
+	}
+
+	if elected, _, err := vs.ElectNextBookkeepers(8); err != nil || elected != nil {
+		t.Errorf("elect not at epoch boundary: %v", err)
+	}
+	elected, addr, err := vs.ElectNextBookkeepers(9)
+	if err != nil || len(elected) != 2 {
+		t.Fatalf("elect next bookkeepers: %v", err)
+	}
+	if !keypair.ComparePublicKey(elected[0], pks[1]) || !keypair.ComparePublicKey(elected[1], pks[0]) {
+		t.Errorf("elected bookkeepers")
+	}
+	if expect, _ := block.AddressFromBookkeepers(elected); addr != expect {
+		t.Errorf("next bookkeeper address: %s", addr.Hex())
+	}
+
+	// alice revokes, bob changes the vote
+	blk = &block.Block{
+		Header: &block.Header{Height: 2},
+		Transactions: []*transaction.Transaction{
+			newVoteTx(alice),
+			newVoteTx(bob, pks[3]),
+		},
+	}
+	if err := vs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	if _, err := vs.GetVote(alice); err != storage.ErrNotFound {
+		t.Errorf("revoked vote: %v", err)
+	}
+	elected, err = vs.Elect()
+	if err != nil || len(elected) != 1 || !keypair.ComparePublicKey(elected[0], pks[3]) {
+		t.Errorf("elect after vote changed: %d, %v", len(elected), err)
+	}
+}
+
+func TestVoteStoreTallyOverflow(t *testing.T) {
+	store := memdb.NewStore()
+	defer store.Close()
+
+	_, pk, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	alice, bob := common.Address{1}, common.Address{2}
+	vs := NewVoteStore(store, testWeights{alice: math.MaxUint64, bob: 1}, 10, 1)
+	blk := &block.Block{
+		Header:       &block.Header{Height: 1},
+		Transactions: []*transaction.Transaction{newVoteTx(alice, pk), newVoteTx(bob, pk)},
+	}
+	if err := vs.ApplyBlock(blk); err != nil {
+		t.Fatalf("apply block: %s", err)
+	}
+	if _, err := vs.Tally(); err != ErrVoteWeightOverflow {
+		t.Errorf("tally with weight overflow: %v", err)
+	}
+}
+
+func TestBookkeeperStoreElection(t *testing.T) {
+	store := memdb.NewStore()
+	defer store.Close()
+
+	_, pks := signaturetest.NewSignatories(t, 2)
+	alice := common.Address{1}
+	vs := NewVoteStore(store, testWeights{alice: 10}, 5, 1)
+	bs := NewBookkeeperStore(store)
+	bs.SetElector(vs)
+	if err := bs.InitBookkeepers(pks[:1]); err != nil {
+		t.Fatalf("init bookkeepers: %s", err)
+	}
+	for h := uint32(1); h < 10; h++ {
+		blk := &block.Block{Header: &block.Header{Height: h}}
+		if h == 2 {
+			blk.Transactions = []*transaction.Transaction{newVoteTx(alice, pks[1])}
+		}
+		if err := vs.ApplyBlock(blk); err != nil {
+			t.Fatalf("apply votes of block %d: %s", h, err)
+		}
+		if err := bs.ApplyBlock(blk); err != nil {
+			t.Fatalf("apply bookkeepers of block %d: %s", h, err)
+		}
+	}
+	// block 4 is the last block of the first epoch
+	for height, pk := range map[uint32]keypair.PublicKey{4: pks[0], 5: pks[1], 9: pks[1]} {
+		bks, err := bs.GetBookkeepers(height)
+		if err != nil || len(bks) != 1 || !keypair.ComparePublicKey(bks[0], pk) {
+			t.Errorf("bookkeepers at %d: %v", height, err)
+		}
+	}
+}
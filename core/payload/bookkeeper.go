@@ -2,12 +2,19 @@ package payload
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"io"
 
+	"github.com/mileschao/echain/common"
 	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/signature"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
+// BookkeeperTxVersionHeight the first version of transaction whose Bookkeeper payload has Height,
+// the payloads of the earlier versions are encoded without it and decoded with Height 0
+const BookkeeperTxVersionHeight = 0x02
+
 //BookkeeperAction bookkeeper action type
 type BookkeeperAction byte
 
@@ -18,61 +25,152 @@ const (
 	BookkeeperActionSUB BookkeeperAction = 1
 )
 
+var bookkeeperActionNames = map[byte]string{
+	byte(BookkeeperActionADD): "ADD",
+	byte(BookkeeperActionSUB): "SUB",
+}
+
+// String get the name of bookkeeper action
+func (action BookkeeperAction) String() string {
+	return common.ByteName(byte(action), bookkeeperActionNames)
+}
+
+// MarshalText implement encoding.TextMarshaler, action is encoded as name
+func (action BookkeeperAction) MarshalText() ([]byte, error) {
+	return []byte(action.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (action *BookkeeperAction) UnmarshalText(text []byte) error {
+	v, err := common.ParseByteName(string(text), bookkeeperActionNames)
+	*action = BookkeeperAction(v)
+	return err
+}
+
 // Bookkeeper is an implementation of transaction payload for consensus bookkeeper list modification
+// the Cert is only valid on the chain and in the block at Height, thus it can not be replayed
 type Bookkeeper struct {
 	PubKey keypair.PublicKey
 	Action BookkeeperAction
+	Height uint32
 	Cert   []byte
 	Issuer keypair.PublicKey
 }
 
+// CertData get the data signed by Issuer as Cert, which is ChainID + Height + Action + PubKey
+func (bk *Bookkeeper) CertData(chainID uint32) []byte {
+	pk := keypair.SerializePublicKey(bk.PubKey)
+	data := make([]byte, 9, 9+len(pk))
+	binary.LittleEndian.PutUint32(data, chainID)
+	binary.LittleEndian.PutUint32(data[4:], bk.Height)
+	data[8] = byte(bk.Action)
+	return append(data, pk...)
+}
+
 // Serialize implement Payload interface
 func (bk *Bookkeeper) Serialize(w io.Writer) error {
-	pk := keypair.SerializePublicKey(bk.PubKey)
-	var bkvb = &serialize.VarBytes{
-		Len:   uint64(len(pk)),
-		Bytes: pk,
+	return serialize.WriteTo(w, bk)
+}
+
+// Deserialize deserialize Bookkeeper from io.Reader
+func (bk *Bookkeeper) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, bk)
+}
+
+// SerializeSink implement Payload interface, Height is always serialized
+func (bk *Bookkeeper) SerializeSink(sink *serialize.Sink) error {
+	return bk.SerializeVersion(sink, BookkeeperTxVersionHeight)
+}
+
+// DeserializeSource implement Payload interface, Height is always deserialized
+func (bk *Bookkeeper) DeserializeSource(source *serialize.Source) error {
+	return bk.DeserializeVersion(source, BookkeeperTxVersionHeight)
+}
+
+// SerializeVersion implement VersionedPayload interface
+// Height is serialized only from BookkeeperTxVersionHeight
+func (bk *Bookkeeper) SerializeVersion(sink *serialize.Sink, txVersion byte) error {
+	sink.WriteVarBytes(keypair.SerializePublicKey(bk.PubKey))
+	sink.WriteUint8(byte(bk.Action))
+	if txVersion >= BookkeeperTxVersionHeight {
+		sink.WriteUint32(bk.Height)
 	}
-	if err := bkvb.Serialize(w); err != nil {
+	sink.WriteVarBytes(bk.Cert)
+	sink.WriteVarBytes(keypair.SerializePublicKey(bk.Issuer))
+	return nil
+}
+
+// DeserializeVersion implement VersionedPayload interface
+// Height is deserialized only from BookkeeperTxVersionHeight, otherwise it is 0
+func (bk *Bookkeeper) DeserializeVersion(source *serialize.Source, txVersion byte) error {
+	pkb, err := source.ReadVarBytes(signature.MaxPubKeySize)
+	if err != nil {
 		return err
 	}
-	binary.Write(w, binary.LittleEndian, bk.Action)
-	var certvb = &serialize.VarBytes{
-		Len:   uint64(len(bk.Cert)),
-		Bytes: bk.Cert,
+	bk.PubKey, err = signature.DeserializePublicKey(pkb)
+	if err != nil {
+		return err
 	}
-	if err := certvb.Serialize(w); err != nil {
+	action, err := source.ReadUint8()
+	if err != nil {
 		return err
 	}
-	issuer := keypair.SerializePublicKey(bk.Issuer)
-	var issuservb = &serialize.VarBytes{
-		Len:   uint64(len(issuer)),
-		Bytes: issuer,
+	bk.Action = BookkeeperAction(action)
+	bk.Height = 0
+	if txVersion >= BookkeeperTxVersionHeight {
+		if bk.Height, err = source.ReadUint32(); err != nil {
+			return err
+		}
 	}
-	return issuservb.Serialize(w)
-}
-
-// Deserialize deserialize Bookkeeper from io.Reader
-func (bk *Bookkeeper) Deserialize(r io.Reader) error {
-	var pkvb serialize.VarBytes
-	if err := pkvb.Deserialize(r); err != nil {
+	bk.Cert, err = source.ReadVarBytes(signature.MaxSignatureSize)
+	if err != nil {
 		return err
 	}
-	var err error
-	bk.PubKey, err = keypair.DeserializePublicKey(pkvb.Bytes)
+	issuer, err := source.ReadVarBytes(signature.MaxPubKeySize)
 	if err != nil {
 		return err
 	}
-	binary.Read(r, binary.LittleEndian, &bk.Action)
-	var certvb serialize.VarBytes
-	if err := certvb.Deserialize(r); err != nil {
+	bk.Issuer, err = signature.DeserializePublicKey(issuer)
+	return err
+}
+
+type bookkeeperJSON struct {
+	PubKey string           `json:"pub_key"`
+	Action BookkeeperAction `json:"action"`
+	Height uint32           `json:"height"`
+	Cert   common.HexBytes  `json:"cert"`
+	Issuer string           `json:"issuer"`
+}
+
+// MarshalJSON implement json.Marshaler
+func (bk Bookkeeper) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&bookkeeperJSON{
+		PubKey: signature.PublicKeyToHex(bk.PubKey),
+		Action: bk.Action,
+		Height: bk.Height,
+		Cert:   bk.Cert,
+		Issuer: signature.PublicKeyToHex(bk.Issuer),
+	})
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+func (bk *Bookkeeper) UnmarshalJSON(data []byte) error {
+	var bj bookkeeperJSON
+	if err := json.Unmarshal(data, &bj); err != nil {
 		return err
 	}
-	bk.Cert = certvb.Bytes
-	var issuevb serialize.VarBytes
-	if err := issuevb.Deserialize(r); err != nil {
+	pubKey, err := signature.PublicKeyFromHex(bj.PubKey)
+	if err != nil {
 		return err
 	}
-	bk.Issuer, err = keypair.DeserializePublicKey(issuevb.Bytes)
-	return err
+	issuer, err := signature.PublicKeyFromHex(bj.Issuer)
+	if err != nil {
+		return err
+	}
+	bk.PubKey = pubKey
+	bk.Action = bj.Action
+	bk.Height = bj.Height
+	bk.Cert = bj.Cert
+	bk.Issuer = issuer
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/mileschao/echain/common/serialize"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -19,6 +20,7 @@ func TestBookkeeperSerialize(t *testing.T) {
 	var bk = &Bookkeeper{
 		PubKey: bkpk,
 		Action: BookkeeperActionADD,
+		Height: 0xFFFE,
 		Cert:   []byte{0xFF},
 		Issuer: ispk,
 	}
@@ -31,7 +33,48 @@ func TestBookkeeperSerialize(t *testing.T) {
 	if err := bk2.Deserialize(buf); err != nil {
 		t.Errorf("book keeper deserialize: %s", err)
 	}
-	if !bytes.Equal(bk.Cert, bk2.Cert) {
+	if !bytes.Equal(bk.Cert, bk2.Cert) || bk2.Height != bk.Height {
 		t.Errorf("book keeper deserialize")
 	}
 }
+
+func TestBookkeeperLegacyDeserialize(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+	// encoding of the payload before Height is added
+	sink := serialize.NewSink(nil)
+	sink.WriteVarBytes(keypair.SerializePublicKey(pk))
+	sink.WriteUint8(byte(BookkeeperActionSUB))
+	sink.WriteVarBytes([]byte{0xFF})
+	sink.WriteVarBytes(keypair.SerializePublicKey(pk))
+	data := sink.Bytes()
+
+	bk := Bookkeeper{Height: 1}
+	source := serialize.NewSource(data)
+	if err := bk.DeserializeVersion(source, 0); err != nil {
+		t.Fatalf("deserialize legacy bookkeeper: %s", err)
+	}
+	if source.Len() != 0 || bk.Height != 0 || bk.Action != BookkeeperActionSUB || !bytes.Equal(bk.Cert, []byte{0xFF}) {
+		t.Errorf("deserialize legacy bookkeeper: %+v", bk)
+	}
+	out := serialize.NewSink(nil)
+	if err := bk.SerializeVersion(out, 0); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("serialize legacy bookkeeper: %v", err)
+	}
+}
+
+func TestBookkeeperCertData(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+	bk := &Bookkeeper{PubKey: pk, Action: BookkeeperActionADD, Height: 1}
+	data := bk.CertData(1)
+	other := *bk
+	other.Height = 2
+	if bytes.Equal(data, bk.CertData(2)) || bytes.Equal(data, other.CertData(1)) {
+		t.Errorf("cert data is not bound to chain and height")
+	}
+}
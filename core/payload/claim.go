@@ -0,0 +1,115 @@
+package payload
+
+import (
+	"io"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+)
+
+const (
+	// MaxClaimInputs max referenced inputs of claim
+	MaxClaimInputs = 1024
+)
+
+// ClaimInput reference to the output of a previous transaction
+type ClaimInput struct {
+	PrevHash  common.Uint256 `json:"prev_hash"`
+	PrevIndex uint16         `json:"prev_index"`
+}
+
+// Serialize implement Serializable interface
+func (ci *ClaimInput) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, ci)
+}
+
+// Deserialize implement Serializable interface
+func (ci *ClaimInput) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, ci)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (ci *ClaimInput) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteBytes(ci.PrevHash[:])
+	sink.WriteUint16(ci.PrevIndex)
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (ci *ClaimInput) DeserializeSource(source *serialize.Source) error {
+	if err := source.ReadFull(ci.PrevHash[:]); err != nil {
+		return err
+	}
+	var err error
+	ci.PrevIndex, err = source.ReadUint16()
+	return err
+}
+
+// Claim claim payload
+// the payer claims the rewards and fees accrued by the referenced inputs
+type Claim struct {
+	Claims []*ClaimInput `json:"claims"`
+}
+
+// IsValid check whether the number of inputs is in (0, MaxClaimInputs] and none of them is nil
+func (c *Claim) IsValid() bool {
+	if len(c.Claims) == 0 || len(c.Claims) > MaxClaimInputs {
+		return false
+	}
+	for _, ci := range c.Claims {
+		if ci == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// HasDuplicateInput check whether any input is referenced more than once
+func (c *Claim) HasDuplicateInput() bool {
+	inputs := make(map[ClaimInput]struct{}, len(c.Claims))
+	for _, ci := range c.Claims {
+		if _, ok := inputs[*ci]; ok {
+			return true
+		}
+		inputs[*ci] = struct{}{}
+	}
+	return false
+}
+
+// Serialize implement Payload interface
+func (c *Claim) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, c)
+}
+
+// Deserialize implement Payload interface
+func (c *Claim) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, c)
+}
+
+// SerializeSink implement Payload interface
+func (c *Claim) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteVarUint(uint64(len(c.Claims)))
+	for _, ci := range c.Claims {
+		if err := ci.SerializeSink(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeserializeSource implement Payload interface
+func (c *Claim) DeserializeSource(source *serialize.Source) error {
+	n, err := source.ReadVarUint(MaxClaimInputs)
+	if err != nil {
+		return err
+	}
+	c.Claims = make([]*ClaimInput, 0, n)
+	for i := uint64(0); i < n; i++ {
+		var ci ClaimInput
+		if err := ci.DeserializeSource(source); err != nil {
+			return err
+		}
+		c.Claims = append(c.Claims, &ci)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package payload
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+)
+
+func TestClaimSerialize(t *testing.T) {
+	var claim = &Claim{
+		Claims: []*ClaimInput{
+			{PrevHash: common.Uint256{0xFF}, PrevIndex: 0},
+			{PrevHash: common.Uint256{0xFF}, PrevIndex: 1},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := claim.Serialize(buf); err != nil {
+		t.Errorf("claim serialize: %s", err)
+	}
+	var claim2 Claim
+	if err := claim2.Deserialize(buf); err != nil {
+		t.Errorf("claim deserialize: %s", err)
+	}
+	if len(claim2.Claims) != 2 || *claim2.Claims[1] != *claim.Claims[1] {
+		t.Errorf("claim deserialize")
+	}
+	if !claim2.IsValid() || claim2.HasDuplicateInput() {
+		t.Errorf("claim is valid")
+	}
+	claim2.Claims[1].PrevIndex = 0
+	if !claim2.HasDuplicateInput() {
+		t.Errorf("claim has duplicate input")
+	}
+}
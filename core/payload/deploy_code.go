@@ -1,8 +1,6 @@
 package payload
 
 import (
-	"bytes"
-	"encoding/binary"
 	"io"
 
 	"github.com/mileschao/echain/common/serialize"
@@ -10,96 +8,90 @@ import (
 	"github.com/mileschao/echain/smartcontract/types"
 )
 
+const (
+	// MaxDeployFieldSize max size of name, version, author and email of deploy code
+	MaxDeployFieldSize = 252
+	// MaxDeployDescriptionSize max size of description of deploy code
+	MaxDeployDescriptionSize = 65535
+)
+
 //DeployCode deploy code payload
 type DeployCode struct {
-	Code        types.VMCode
-	NeedStorage bool
-	Name        string
-	Version     string
-	Author      string
-	Email       string
-	Description string
+	Code        types.VMCode `json:"code"`
+	NeedStorage bool         `json:"need_storage"`
+	Name        string       `json:"name"`
+	Version     string       `json:"version"`
+	Author      string       `json:"author"`
+	Email       string       `json:"email"`
+	Description string       `json:"description"`
+}
+
+// IsValid check whether the code is valid and the fields are not too long
+func (dc *DeployCode) IsValid() bool {
+	return isValidVMCode(&dc.Code) &&
+		len(dc.Name) <= MaxDeployFieldSize &&
+		len(dc.Version) <= MaxDeployFieldSize &&
+		len(dc.Author) <= MaxDeployFieldSize &&
+		len(dc.Email) <= MaxDeployFieldSize &&
+		len(dc.Description) <= MaxDeployDescriptionSize
+}
+
+// isValidVMCode check whether the code is not empty and the vm type is known
+func isValidVMCode(code *types.VMCode) bool {
+	return len(code.Code) > 0 && code.VMType.IsValid()
 }
 
 // Serialize implement Payload interface
 func (dc *DeployCode) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, dc)
+}
 
-	if err := dc.Code.Serialize(w); err != nil {
-		return err
-	}
-	binary.Write(w, binary.LittleEndian, dc.NeedStorage)
-	var nvb = &serialize.VarBytes{
-		Len:   uint64(len(dc.Name)),
-		Bytes: []byte(dc.Name),
-	}
-	if err := nvb.Serialize(w); err != nil {
-		return err
-	}
-	var vervb = &serialize.VarBytes{
-		Len:   uint64(len(dc.Version)),
-		Bytes: []byte(dc.Version),
-	}
-	if err := vervb.Serialize(w); err != nil {
-		return err
-	}
-	var authorvb = &serialize.VarBytes{
-		Len:   uint64(len(dc.Author)),
-		Bytes: []byte(dc.Author),
-	}
-	if err := authorvb.Serialize(w); err != nil {
-		return err
-	}
-	var emailvb = &serialize.VarBytes{
-		Len:   uint64(len(dc.Email)),
-		Bytes: []byte(dc.Email),
-	}
-	if err := emailvb.Serialize(w); err != nil {
+//Deserialize implement Payload interface
+func (dc *DeployCode) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, dc)
+}
+
+// SerializeSink implement Payload interface
+func (dc *DeployCode) SerializeSink(sink *serialize.Sink) error {
+	if err := dc.Code.SerializeSink(sink); err != nil {
 		return err
 	}
-	var descvb = &serialize.VarBytes{
-		Len:   uint64(len(dc.Description)),
-		Bytes: []byte(dc.Description),
-	}
-	return descvb.Serialize(w)
+	sink.WriteBool(dc.NeedStorage)
+	sink.WriteString(dc.Name)
+	sink.WriteString(dc.Version)
+	sink.WriteString(dc.Author)
+	sink.WriteString(dc.Email)
+	sink.WriteString(dc.Description)
+	return nil
 }
 
-//Deserialize implement Payload interface
-func (dc *DeployCode) Deserialize(r io.Reader) error {
-	if err := dc.Code.Deserialize(r); err != nil {
+// DeserializeSource implement Payload interface
+func (dc *DeployCode) DeserializeSource(source *serialize.Source) error {
+	if err := dc.Code.DeserializeSource(source); err != nil {
 		return err
 	}
-	binary.Read(r, binary.LittleEndian, &dc.NeedStorage)
-	var nvb serialize.VarBytes
-	if err := nvb.Deserialize(r); err != nil {
+	var err error
+	if dc.NeedStorage, err = source.ReadBool(); err != nil {
 		return err
 	}
-	dc.Name = string(nvb.Bytes)
-	var vervb serialize.VarBytes
-	if err := vervb.Deserialize(r); err != nil {
+	if dc.Name, err = source.ReadString(serialize.MaxVarBytesLen); err != nil {
 		return err
 	}
-	dc.Version = string(vervb.Bytes)
-	var authorvb serialize.VarBytes
-	if err := authorvb.Deserialize(r); err != nil {
+	if dc.Version, err = source.ReadString(serialize.MaxVarBytesLen); err != nil {
 		return err
 	}
-	dc.Author = string(authorvb.Bytes)
-	var emailvb serialize.VarBytes
-	if err := emailvb.Deserialize(r); err != nil {
+	if dc.Author, err = source.ReadString(serialize.MaxVarBytesLen); err != nil {
 		return err
 	}
-	dc.Email = string(emailvb.Bytes)
-	var descvb serialize.VarBytes
-	if err := descvb.Deserialize(r); err != nil {
+	if dc.Email, err = source.ReadString(serialize.MaxVarBytesLen); err != nil {
 		return err
 	}
-	dc.Description = string(descvb.Bytes)
-	return nil
+	dc.Description, err = source.ReadString(serialize.MaxVarBytesLen)
+	return err
 }
 
 //Bytes get byte array
 func (dc *DeployCode) Bytes() []byte {
-	b := new(bytes.Buffer)
-	dc.Serialize(b)
-	return b.Bytes()
+	b, _ := serialize.ToBytes(dc)
+	return b
 }
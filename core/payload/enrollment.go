@@ -0,0 +1,81 @@
+package payload
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// Enrollment candidate enrollment payload
+// the owner of PubKey registers to be a bookkeeper candidate with Deposit locked
+type Enrollment struct {
+	PubKey  keypair.PublicKey
+	Deposit uint64
+}
+
+// IsValid check whether the candidate public key is set and the deposit is not zero
+func (e *Enrollment) IsValid() bool {
+	return e.PubKey != nil && e.Deposit > 0
+}
+
+// Serialize implement Payload interface
+func (e *Enrollment) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, e)
+}
+
+// Deserialize implement Payload interface
+func (e *Enrollment) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, e)
+}
+
+// SerializeSink implement Payload interface
+func (e *Enrollment) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteVarBytes(keypair.SerializePublicKey(e.PubKey))
+	sink.WriteUint64(e.Deposit)
+	return nil
+}
+
+// DeserializeSource implement Payload interface
+func (e *Enrollment) DeserializeSource(source *serialize.Source) error {
+	pkb, err := source.ReadVarBytes(signature.MaxPubKeySize)
+	if err != nil {
+		return err
+	}
+	e.PubKey, err = signature.DeserializePublicKey(pkb)
+	if err != nil {
+		return err
+	}
+	e.Deposit, err = source.ReadUint64()
+	return err
+}
+
+type enrollmentJSON struct {
+	PubKey  string `json:"pub_key"`
+	Deposit uint64 `json:"deposit"`
+}
+
+// MarshalJSON implement json.Marshaler
+func (e Enrollment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&enrollmentJSON{
+		PubKey:  signature.PublicKeyToHex(e.PubKey),
+		Deposit: e.Deposit,
+	})
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+func (e *Enrollment) UnmarshalJSON(data []byte) error {
+	var ej enrollmentJSON
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return err
+	}
+	pubKey, err := signature.PublicKeyFromHex(ej.PubKey)
+	if err != nil {
+		return err
+	}
+	e.PubKey = pubKey
+	e.Deposit = ej.Deposit
+	return nil
+}
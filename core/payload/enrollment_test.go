@@ -0,0 +1,37 @@
+package payload
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func TestEnrollmentSerialize(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P224)
+	if err != nil {
+		t.Errorf("generate key: %s", err)
+	}
+	var enroll = &Enrollment{
+		PubKey:  pk,
+		Deposit: 1000,
+	}
+	buf := new(bytes.Buffer)
+	if err := enroll.Serialize(buf); err != nil {
+		t.Errorf("enrollment serialize: %s", err)
+	}
+	var enroll2 Enrollment
+	if err := enroll2.Deserialize(buf); err != nil {
+		t.Errorf("enrollment deserialize: %s", err)
+	}
+	if !keypair.ComparePublicKey(enroll.PubKey, enroll2.PubKey) || enroll2.Deposit != enroll.Deposit {
+		t.Errorf("enrollment deserialize")
+	}
+	if !enroll2.IsValid() {
+		t.Errorf("enrollment is valid")
+	}
+	enroll2.Deposit = 0
+	if enroll2.IsValid() {
+		t.Errorf("enrollment without deposit is valid")
+	}
+}
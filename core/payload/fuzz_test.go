@@ -0,0 +1,64 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/common/serialize/serializetest"
+	"github.com/mileschao/echain/smartcontract/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func fuzzPubKey(f *testing.F) keypair.PublicKey {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		f.Fatalf("generate public key: %s", err)
+	}
+	return pk
+}
+
+func FuzzBookkeeper(f *testing.F) {
+	pk := fuzzPubKey(f)
+	serializetest.AddSeeds(f, &Bookkeeper{PubKey: pk, Action: BookkeeperActionADD, Cert: []byte{0xFF}, Issuer: pk})
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Bookkeeper) })
+}
+
+func FuzzDeployCode(f *testing.F) {
+	serializetest.AddSeeds(f, &DeployCode{
+		Code:        types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}},
+		NeedStorage: true,
+		Name:        "n",
+		Version:     "v",
+		Author:      "a",
+		Email:       "e",
+		Description: "d",
+	})
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(DeployCode) })
+}
+
+func FuzzInvokeCode(f *testing.F) {
+	serializetest.AddSeeds(f, &InvokeCode{Code: types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}})
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(InvokeCode) })
+}
+
+func FuzzVote(f *testing.F) {
+	serializetest.AddSeeds(f,
+		&Vote{},
+		&Vote{PubKeys: []keypair.PublicKey{fuzzPubKey(f), fuzzPubKey(f)}, Account: common.Address{0xFF}},
+	)
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Vote) })
+}
+
+func FuzzEnrollment(f *testing.F) {
+	serializetest.AddSeeds(f, &Enrollment{PubKey: fuzzPubKey(f), Deposit: 1000})
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Enrollment) })
+}
+
+func FuzzClaim(f *testing.F) {
+	serializetest.AddSeeds(f,
+		&Claim{},
+		&Claim{Claims: []*ClaimInput{{PrevHash: common.Uint256{0xFF}, PrevIndex: 1}}},
+	)
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Claim) })
+}
@@ -3,20 +3,36 @@ package payload
 import (
 	"io"
 
+	"github.com/mileschao/echain/common/serialize"
 	"github.com/mileschao/echain/smartcontract/types"
 )
 
 //InvokeCode invoke code
 type InvokeCode struct {
-	Code types.VMCode
+	Code types.VMCode `json:"code"`
+}
+
+// IsValid check whether the code is not empty and the vm type is known
+func (ic *InvokeCode) IsValid() bool {
+	return isValidVMCode(&ic.Code)
 }
 
 // Serialize implement Payload interface
 func (ic *InvokeCode) Serialize(w io.Writer) error {
-	return ic.Code.Serialize(w)
+	return serialize.WriteTo(w, ic)
 }
 
 // Deserialize implement Payload interface
 func (ic *InvokeCode) Deserialize(r io.Reader) error {
-	return ic.Code.Deserialize(r)
+	return serialize.ReadFrom(r, ic)
+}
+
+// SerializeSink implement Payload interface
+func (ic *InvokeCode) SerializeSink(sink *serialize.Sink) error {
+	return ic.Code.SerializeSink(sink)
+}
+
+// DeserializeSource implement Payload interface
+func (ic *InvokeCode) DeserializeSource(source *serialize.Source) error {
+	return ic.Code.DeserializeSource(source)
 }
@@ -4,4 +4,13 @@ import "github.com/mileschao/echain/common/serialize"
 
 // Payload define the func for loading the payload data
 // base on payload type which have different struture
+// the payload may implement serialize.SinkSerializable for the fast path of Sink/Source
 type Payload = serialize.Serializable
+
+// VersionedPayload payload whose encoding depends on the version of transaction,
+// the transaction serializes and deserializes it with its version by these methods
+type VersionedPayload interface {
+	Payload
+	SerializeVersion(sink *serialize.Sink, txVersion byte) error
+	DeserializeVersion(source *serialize.Source, txVersion byte) error
+}
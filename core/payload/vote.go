@@ -1,11 +1,13 @@
 package payload
 
 import (
+	"encoding/json"
 	"io"
 
 	"github.com/mileschao/echain/common/serialize"
 
 	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/signature"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -21,8 +23,8 @@ type Vote struct {
 	Account common.Address
 }
 
-//isValid
-func (v *Vote) isValid() bool {
+// IsValid check whether the number of vote public keys is not more than MaxVoteKeys
+func (v *Vote) IsValid() bool {
 	if len(v.PubKeys) > MaxVoteKeys {
 		return false
 	}
@@ -31,43 +33,69 @@ func (v *Vote) isValid() bool {
 
 // Serialize implement Payload interface
 func (v *Vote) Serialize(w io.Writer) error {
-	var pkvu = &serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(v.PubKeys))),
-		Value:    uint64(len(v.PubKeys)),
-	}
-	if err := pkvu.Serialize(w); err != nil {
-		return err
-	}
-	for _, pk := range v.PubKeys {
-		buf := keypair.SerializePublicKey(pk)
-		var pkvb = &serialize.VarBytes{
-			Len:   uint64(len(buf)),
-			Bytes: buf,
-		}
-		if err := pkvb.Serialize(w); err != nil {
-			return err
-		}
-	}
-	return v.Account.Serialize(w)
+	return serialize.WriteTo(w, v)
 }
 
 //Deserialize implement Payload interface
 func (v *Vote) Deserialize(r io.Reader) error {
-	var pkvu serialize.VarUint
-	if err := pkvu.Deserialize(r); err != nil {
+	return serialize.ReadFrom(r, v)
+}
+
+// SerializeSink implement Payload interface
+func (v *Vote) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteVarUint(uint64(len(v.PubKeys)))
+	for _, pk := range v.PubKeys {
+		sink.WriteVarBytes(keypair.SerializePublicKey(pk))
+	}
+	sink.WriteBytes(v.Account[:])
+	return nil
+}
+
+// DeserializeSource implement Payload interface
+func (v *Vote) DeserializeSource(source *serialize.Source) error {
+	n, err := source.ReadVarUint(MaxVoteKeys)
+	if err != nil {
 		return err
 	}
-	v.PubKeys = make([]keypair.PublicKey, 0)
-	for i := uint64(0); i < pkvu.Value; i++ {
-		var pkvb serialize.VarBytes
-		if err := pkvb.Deserialize(r); err != nil {
+	v.PubKeys = make([]keypair.PublicKey, 0, n)
+	for i := uint64(0); i < n; i++ {
+		pkb, err := source.ReadVarBytes(signature.MaxPubKeySize)
+		if err != nil {
 			return err
 		}
-		pk, err := keypair.DeserializePublicKey(pkvb.Bytes)
+		pk, err := signature.DeserializePublicKey(pkb)
 		if err != nil {
 			return err
 		}
 		v.PubKeys = append(v.PubKeys, pk)
 	}
-	return v.Account.Deserialize(r)
+	return source.ReadFull(v.Account[:])
+}
+
+type voteJSON struct {
+	PubKeys []string       `json:"pub_keys"`
+	Account common.Address `json:"account"`
+}
+
+// MarshalJSON implement json.Marshaler
+func (v Vote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&voteJSON{
+		PubKeys: signature.PublicKeysToHex(v.PubKeys),
+		Account: v.Account,
+	})
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+func (v *Vote) UnmarshalJSON(data []byte) error {
+	var vj voteJSON
+	if err := json.Unmarshal(data, &vj); err != nil {
+		return err
+	}
+	pubKeys, err := signature.PublicKeysFromHex(vj.PubKeys)
+	if err != nil {
+		return err
+	}
+	v.PubKeys = pubKeys
+	v.Account = vj.Account
+	return nil
 }
@@ -1,12 +1,21 @@
 package signature
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	ontsig "github.com/ontio/ontology-crypto/signature"
 )
 
+const (
+	// MaxPubKeySize max size of serialized public key
+	MaxPubKeySize = 256
+	// MaxSignatureSize max size of serialized signature
+	MaxSignatureSize = 256
+)
+
 var (
 	//ErrVerifySign verify signature failed
 	ErrVerifySign = errors.New("signature verification failed")
@@ -14,6 +23,8 @@ var (
 	ErrInvalidSignData = errors.New("invalid signature data")
 	//ErrNotEnoughtSignature not enought signature
 	ErrNotEnoughtSignature = errors.New("not enought signature")
+	//ErrNonCanonicalPubKey public key is not encoded as keypair.SerializePublicKey does
+	ErrNonCanonicalPubKey = errors.New("non-canonical public key")
 )
 
 //Signatory the one who sign
@@ -81,3 +92,59 @@ func VerifyMultiSignature(data []byte, keys []keypair.PublicKey, m int, sigs [][
 	}
 	return nil
 }
+
+// DeserializePublicKey deserialize public key of untrusted source,
+// the data is rejected unless it is the serialized form of the key
+func DeserializePublicKey(data []byte) (keypair.PublicKey, error) {
+	if len(data) > MaxPubKeySize {
+		return nil, ErrNonCanonicalPubKey
+	}
+	pk, err := keypair.DeserializePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(keypair.SerializePublicKey(pk), data) {
+		return nil, ErrNonCanonicalPubKey
+	}
+	return pk, nil
+}
+
+// PublicKeysToHex get the hex strings of serialized public keys, i.e. for JSON
+func PublicKeysToHex(pubKeys []keypair.PublicKey) []string {
+	s := make([]string, 0, len(pubKeys))
+	for _, pk := range pubKeys {
+		s = append(s, PublicKeyToHex(pk))
+	}
+	return s
+}
+
+// PublicKeysFromHex get public keys from hex strings, see PublicKeysToHex as reference
+func PublicKeysFromHex(s []string) ([]keypair.PublicKey, error) {
+	pubKeys := make([]keypair.PublicKey, 0, len(s))
+	for _, pkh := range s {
+		pk, err := PublicKeyFromHex(pkh)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, pk)
+	}
+	return pubKeys, nil
+}
+
+// PublicKeyToHex get the hex string of serialized public key
+func PublicKeyToHex(pubKey keypair.PublicKey) string {
+	return hex.EncodeToString(keypair.SerializePublicKey(pubKey))
+}
+
+// PublicKeyFromHex get public key from hex string of serialized public key,
+// nil returned for empty string
+func PublicKeyFromHex(s string) (keypair.PublicKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return DeserializePublicKey(data)
+}
@@ -0,0 +1,51 @@
+// Package signaturetest signatory helpers for the tests signing with signature.Signatory
+package signaturetest
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	ontsig "github.com/ontio/ontology-crypto/signature"
+)
+
+// Signatory signature.Signatory of an generated ECDSA P256 key pair
+type Signatory struct {
+	priKey keypair.PrivateKey
+	pubKey keypair.PublicKey
+}
+
+// PrivateKey implement signature.Signatory interface
+func (s *Signatory) PrivateKey() keypair.PrivateKey {
+	return s.priKey
+}
+
+// PublicKey implement signature.Signatory interface
+func (s *Signatory) PublicKey() keypair.PublicKey {
+	return s.pubKey
+}
+
+// Scheme implement signature.Signatory interface
+func (s *Signatory) Scheme() ontsig.SignatureScheme {
+	return ontsig.SHA256withECDSA
+}
+
+// NewSignatory generate an new signatory, tb fails if the key pair can not be generated
+func NewSignatory(tb testing.TB) *Signatory {
+	pri, pub, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		tb.Fatalf("generate key pair: %s", err)
+	}
+	return &Signatory{priKey: pri, pubKey: pub}
+}
+
+// NewSignatories generate n signatories and their public keys in the same order
+func NewSignatories(tb testing.TB, n int) ([]*Signatory, []keypair.PublicKey) {
+	signers := make([]*Signatory, 0, n)
+	pks := make([]keypair.PublicKey, 0, n)
+	for i := 0; i < n; i++ {
+		s := NewSignatory(tb)
+		signers = append(signers, s)
+		pks = append(pks, s.PublicKey())
+	}
+	return signers, pks
+}
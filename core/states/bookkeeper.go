@@ -0,0 +1,71 @@
+package states
+
+import (
+	"io"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// BookkeeperState bookkeeper list of consensus
+// CurrBookkeeper signs the current block, NextBookkeeper signs the blocks after it
+type BookkeeperState struct {
+	CurrBookkeeper []keypair.PublicKey
+	NextBookkeeper []keypair.PublicKey
+}
+
+// Serialize implement Serializable interface
+func (bs *BookkeeperState) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, bs)
+}
+
+// Deserialize implement Serializable interface
+func (bs *BookkeeperState) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, bs)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (bs *BookkeeperState) SerializeSink(sink *serialize.Sink) error {
+	serializePubKeys(sink, bs.CurrBookkeeper)
+	serializePubKeys(sink, bs.NextBookkeeper)
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (bs *BookkeeperState) DeserializeSource(source *serialize.Source) error {
+	var err error
+	if bs.CurrBookkeeper, err = deserializePubKeys(source); err != nil {
+		return err
+	}
+	bs.NextBookkeeper, err = deserializePubKeys(source)
+	return err
+}
+
+func serializePubKeys(sink *serialize.Sink, pubKeys []keypair.PublicKey) {
+	sink.WriteVarUint(uint64(len(pubKeys)))
+	for _, pk := range pubKeys {
+		sink.WriteVarBytes(keypair.SerializePublicKey(pk))
+	}
+}
+
+func deserializePubKeys(source *serialize.Source) ([]keypair.PublicKey, error) {
+	n, err := source.ReadVarUint(common.MAX_MULTI_PUBKEYS)
+	if err != nil {
+		return nil, err
+	}
+	pubKeys := make([]keypair.PublicKey, 0, n)
+	for i := uint64(0); i < n; i++ {
+		pkb, err := source.ReadVarBytes(signature.MaxPubKeySize)
+		if err != nil {
+			return nil, err
+		}
+		pk, err := signature.DeserializePublicKey(pkb)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, pk)
+	}
+	return pubKeys, nil
+}
@@ -0,0 +1,29 @@
+package states
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func TestBookkeeperStateSerialize(t *testing.T) {
+	_, pk1, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	_, pk2, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	var state = &BookkeeperState{
+		CurrBookkeeper: []keypair.PublicKey{pk1},
+		NextBookkeeper: []keypair.PublicKey{pk1, pk2},
+	}
+	buf := new(bytes.Buffer)
+	if err := state.Serialize(buf); err != nil {
+		t.Errorf("bookkeeper state serialize: %s", err)
+	}
+	var state2 BookkeeperState
+	if err := state2.Deserialize(buf); err != nil {
+		t.Errorf("bookkeeper state deserialize: %s", err)
+	}
+	if len(state2.CurrBookkeeper) != 1 || len(state2.NextBookkeeper) != 2 ||
+		!keypair.ComparePublicKey(state2.NextBookkeeper[1], pk2) {
+		t.Errorf("bookkeeper state deserialize")
+	}
+}
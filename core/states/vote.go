@@ -0,0 +1,36 @@
+package states
+
+import (
+	"io"
+
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// VoteState the latest vote of account
+type VoteState struct {
+	PubKeys []keypair.PublicKey
+}
+
+// Serialize implement Serializable interface
+func (vs *VoteState) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, vs)
+}
+
+// Deserialize implement Serializable interface
+func (vs *VoteState) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, vs)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (vs *VoteState) SerializeSink(sink *serialize.Sink) error {
+	serializePubKeys(sink, vs.PubKeys)
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (vs *VoteState) DeserializeSource(source *serialize.Source) error {
+	var err error
+	vs.PubKeys, err = deserializePubKeys(source)
+	return err
+}
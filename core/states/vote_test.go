@@ -0,0 +1,24 @@
+package states
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func TestVoteStateSerialize(t *testing.T) {
+	_, pk, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	var state = &VoteState{PubKeys: []keypair.PublicKey{pk}}
+	buf := new(bytes.Buffer)
+	if err := state.Serialize(buf); err != nil {
+		t.Errorf("vote state serialize: %s", err)
+	}
+	var state2 VoteState
+	if err := state2.Deserialize(buf); err != nil {
+		t.Errorf("vote state deserialize: %s", err)
+	}
+	if len(state2.PubKeys) != 1 || !keypair.ComparePublicKey(state2.PubKeys[0], pk) {
+		t.Errorf("vote state deserialize")
+	}
+}
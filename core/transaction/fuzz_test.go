@@ -0,0 +1,57 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/common/serialize/serializetest"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/smartcontract/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func FuzzTransaction(f *testing.F) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		f.Fatalf("generate public key: %s", err)
+	}
+	attr := NewTxAttribute(Nonce, []byte{0xFF})
+	invoke := testInvokeTx(f, []byte{0xFF})
+	invoke.SetChainID(1)
+	invoke.Attributes = []*TxAttribute{&attr}
+	invoke.Sigs = []*Sig{{PubKeys: []keypair.PublicKey{pk}, M: 1, SigData: [][]byte{{0xFF}}}}
+	serializetest.AddSeeds(f,
+		invoke,
+		&Transaction{TxType: Bookkeeper, Payload: &payload.Bookkeeper{
+			PubKey: pk,
+			Action: payload.BookkeeperActionADD,
+			Cert:   []byte{0xFF},
+			Issuer: pk,
+		}},
+		testDeployTx(f, types.NEOVM),
+		&Transaction{TxType: Vote, Payload: &payload.Vote{PubKeys: []keypair.PublicKey{pk}}},
+		testEnrollmentTx(f, pk),
+		testClaimTx(f),
+	)
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Transaction) })
+}
+
+func FuzzTxAttribute(f *testing.F) {
+	for _, usage := range []TxAttrUsage{Nonce, Script, DescriptionURL, Description} {
+		attr := NewTxAttribute(usage, []byte{0xFF})
+		serializetest.AddSeeds(f, &attr)
+	}
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(TxAttribute) })
+}
+
+func FuzzSig(f *testing.F) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		f.Fatalf("generate public key: %s", err)
+	}
+	serializetest.AddSeeds(f,
+		&Sig{},
+		&Sig{PubKeys: []keypair.PublicKey{pk}, M: 1, SigData: [][]byte{{0xFF}}},
+	)
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(Sig) })
+}
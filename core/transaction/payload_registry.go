@@ -0,0 +1,50 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mileschao/echain/core/payload"
+)
+
+var (
+	// ErrNilPayload transaction without payload
+	ErrNilPayload = errors.New("transaction has no payload")
+	// ErrInvalidPayload invalid arguments of transaction payload
+	ErrInvalidPayload = errors.New("invalid transaction payload")
+)
+
+// PayloadCreator create an empty payload to be deserialized
+type PayloadCreator func() payload.Payload
+
+var (
+	payloadLock     sync.RWMutex
+	payloadCreators = map[TxType]PayloadCreator{
+		Bookkeeper: func() payload.Payload { return new(payload.Bookkeeper) },
+		Claim:      func() payload.Payload { return new(payload.Claim) },
+		Deploy:     func() payload.Payload { return new(payload.DeployCode) },
+		Invoke:     func() payload.Payload { return new(payload.InvokeCode) },
+		Enrollment: func() payload.Payload { return new(payload.Enrollment) },
+		Vote:       func() payload.Payload { return new(payload.Vote) },
+	}
+)
+
+// RegisterPayload register payload creator of transaction type
+// the existing creator of txType is replaced, so builtin types can be overridden
+func RegisterPayload(txType TxType, creator PayloadCreator) {
+	payloadLock.Lock()
+	defer payloadLock.Unlock()
+	payloadCreators[txType] = creator
+}
+
+// NewPayload create an empty payload of transaction type
+func NewPayload(txType TxType) (payload.Payload, error) {
+	payloadLock.RLock()
+	creator, ok := payloadCreators[txType]
+	payloadLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction type: 0x%02x", byte(txType))
+	}
+	return creator(), nil
+}
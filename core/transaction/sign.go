@@ -0,0 +1,111 @@
+package transaction
+
+import (
+	"errors"
+
+	"github.com/mileschao/echain/core/signature"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+var (
+	// ErrNoSignature transaction without any signature
+	ErrNoSignature = errors.New("transaction has no signature")
+	// ErrSigThreshold the m of m-of-n signature is out of range
+	ErrSigThreshold = errors.New("invalid signature threshold")
+	// ErrPayerNotSigned payer of transaction is not the signer
+	ErrPayerNotSigned = errors.New("payer has not signed the transaction")
+)
+
+// SignTransaction sign the transaction by signatory and append the signature into tx.Sigs
+func SignTransaction(tx *Transaction, signatory signature.Signatory) error {
+	hash := tx.Hash()
+	sigData, err := signature.Sign(signatory, hash[:])
+	if err != nil {
+		return err
+	}
+	tx.Sigs = append(tx.Sigs, &Sig{
+		PubKeys: []keypair.PublicKey{signatory.PublicKey()},
+		M:       1,
+		SigData: [][]byte{sigData},
+	})
+	return nil
+}
+
+// MultiSignTransaction sign the transaction by signatory as one of the m-of-n public keys
+// the signature is appended to the Sig with the same public keys if it exists,
+// thus every owner of public keys can sign the transaction in turn
+func MultiSignTransaction(tx *Transaction, m uint8, pubKeys []keypair.PublicKey, signatory signature.Signatory) error {
+	if m == 0 || int(m) > len(pubKeys) {
+		return ErrSigThreshold
+	}
+	hash := tx.Hash()
+	sigData, err := signature.Sign(signatory, hash[:])
+	if err != nil {
+		return err
+	}
+	for _, sig := range tx.Sigs {
+		if sig.M == m && samePubKeys(sig.PubKeys, pubKeys) {
+			sig.SigData = append(sig.SigData, sigData)
+			return nil
+		}
+	}
+	tx.Sigs = append(tx.Sigs, &Sig{
+		PubKeys: pubKeys,
+		M:       m,
+		SigData: [][]byte{sigData},
+	})
+	return nil
+}
+
+// VerifyTransactionSignatures check every Sig of the transaction against tx.Hash(),
+// and the payer of transaction must be the address of one Sig
+func VerifyTransactionSignatures(tx *Transaction) error {
+	if len(tx.Sigs) == 0 {
+		return ErrNoSignature
+	}
+	hash := tx.Hash()
+	payerSigned := false
+	for _, sig := range tx.Sigs {
+		if err := sig.Verify(hash[:]); err != nil {
+			return err
+		}
+		addr, err := sig.Address()
+		if err != nil {
+			return err
+		}
+		if addr == tx.Payer {
+			payerSigned = true
+		}
+	}
+	if !payerSigned {
+		return ErrPayerNotSigned
+	}
+	return nil
+}
+
+// Verify check the signature data against data
+// single public key Sig need 1 signature, m-of-n Sig need m signatures
+func (s *Sig) Verify(data []byte) error {
+	if s.M == 0 || int(s.M) > len(s.PubKeys) {
+		return ErrSigThreshold
+	}
+	if len(s.PubKeys) == 1 {
+		if len(s.SigData) == 0 {
+			return signature.ErrNotEnoughtSignature
+		}
+		return signature.Verify(s.PubKeys[0], data, s.SigData[0])
+	}
+	return signature.VerifyMultiSignature(data, s.PubKeys, int(s.M), s.SigData)
+}
+
+func samePubKeys(pks1, pks2 []keypair.PublicKey) bool {
+	if len(pks1) != len(pks2) {
+		return false
+	}
+	for i := range pks1 {
+		if !keypair.ComparePublicKey(pks1[i], pks2[i]) {
+			return false
+		}
+	}
+	return true
+}
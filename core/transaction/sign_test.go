@@ -0,0 +1,83 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/core/signature/signaturetest"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func newTestTx(t *testing.T) *Transaction {
+	tx := testInvokeTx(t, []byte{0xFF})
+	tx.GasPrice = 1
+	tx.GasLimit = 10000
+	return tx
+}
+
+func TestSignTransaction(t *testing.T) {
+	signer := signaturetest.NewSignatory(t)
+	tx := newTestTx(t)
+	sig := &Sig{PubKeys: []keypair.PublicKey{signer.PublicKey()}, M: 1}
+	payer, err := sig.Address()
+	if err != nil {
+		t.Fatalf("signature address: %s", err)
+	}
+	tx.Payer = payer
+	if err := VerifyTransactionSignatures(tx); err != ErrNoSignature {
+		t.Errorf("verify unsigned transaction: %v", err)
+	}
+	if err := SignTransaction(tx, signer); err != nil {
+		t.Fatalf("sign transaction: %s", err)
+	}
+	if err := VerifyTransactionSignatures(tx); err != nil {
+		t.Errorf("verify transaction: %s", err)
+	}
+
+	tampered := *tx
+	tampered.hash = nil
+	tampered.GasPrice++
+	if err := VerifyTransactionSignatures(&tampered); err == nil {
+		t.Errorf("verify tampered transaction")
+	}
+
+	other := *tx
+	other.hash = nil
+	other.Payer[0] ^= 0xFF
+	other.Sigs = nil
+	if err := SignTransaction(&other, signer); err != nil {
+		t.Fatalf("sign transaction: %s", err)
+	}
+	if err := VerifyTransactionSignatures(&other); err != ErrPayerNotSigned {
+		t.Errorf("verify transaction with other payer: %v", err)
+	}
+}
+
+func TestMultiSignTransaction(t *testing.T) {
+	signers, pubKeys := signaturetest.NewSignatories(t, 3)
+	tx := newTestTx(t)
+	sig := &Sig{PubKeys: pubKeys, M: 2}
+	payer, err := sig.Address()
+	if err != nil {
+		t.Fatalf("signature address: %s", err)
+	}
+	tx.Payer = payer
+
+	if err := MultiSignTransaction(tx, 4, pubKeys, signers[0]); err != ErrSigThreshold {
+		t.Errorf("multi sign with wrong threshold: %v", err)
+	}
+	if err := MultiSignTransaction(tx, 2, pubKeys, signers[2]); err != nil {
+		t.Fatalf("multi sign transaction: %s", err)
+	}
+	if err := VerifyTransactionSignatures(tx); err == nil {
+		t.Errorf("verify transaction with 1 of 2 signatures")
+	}
+	if err := MultiSignTransaction(tx, 2, pubKeys, signers[0]); err != nil {
+		t.Fatalf("multi sign transaction: %s", err)
+	}
+	if len(tx.Sigs) != 1 || len(tx.Sigs[0].SigData) != 2 {
+		t.Errorf("multi sign transaction: %d sigs", len(tx.Sigs))
+	}
+	if err := VerifyTransactionSignatures(tx); err != nil {
+		t.Errorf("verify multi signed transaction: %s", err)
+	}
+}
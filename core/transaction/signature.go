@@ -1,10 +1,12 @@
 package transaction
 
 import (
-	"encoding/binary"
+	"encoding/json"
 	"io"
 
+	"github.com/mileschao/echain/common"
 	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/signature"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -17,81 +19,110 @@ type Sig struct {
 
 //Serialize implement Payload interface
 func (s *Sig) Serialize(w io.Writer) error {
-	var pkvu = &serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(s.PubKeys))),
-		Value:    uint64(len(s.PubKeys)),
-	}
-	if err := pkvu.Serialize(w); err != nil {
-		return err
-	}
+	return serialize.WriteTo(w, s)
+}
 
+//Deserialize implement Payload interface
+func (s *Sig) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, s)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (s *Sig) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteVarUint(uint64(len(s.PubKeys)))
 	for _, pk := range s.PubKeys {
-		pkb := keypair.SerializePublicKey(pk)
-		var pkvb = serialize.VarBytes{
-			Len:   uint64(len(pkb)),
-			Bytes: pkb,
-		}
-		pkvb.Serialize(w)
+		sink.WriteVarBytes(keypair.SerializePublicKey(pk))
 	}
-
-	binary.Write(w, binary.LittleEndian, s.M)
-
-	var sdvu = &serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(s.SigData))),
-		Value:    uint64(len(s.SigData)),
+	sink.WriteUint8(s.M)
+	sink.WriteVarUint(uint64(len(s.SigData)))
+	for _, sig := range s.SigData {
+		sink.WriteVarBytes(sig)
 	}
+	return nil
+}
 
-	if err := sdvu.Serialize(w); err != nil {
+// DeserializeSource implement SinkSerializable interface
+func (s *Sig) DeserializeSource(source *serialize.Source) error {
+	n, err := source.ReadVarUint(common.MAX_MULTI_PUBKEYS)
+	if err != nil {
 		return err
 	}
-
-	for _, sig := range s.SigData {
-		var sgvb = &serialize.VarBytes{
-			Len:   uint64(len(sig)),
-			Bytes: sig,
+	s.PubKeys = make([]keypair.PublicKey, 0, n)
+	for i := uint64(0); i < n; i++ {
+		pkb, err := source.ReadVarBytes(signature.MaxPubKeySize)
+		if err != nil {
+			return err
 		}
-		if err := sgvb.Serialize(w); err != nil {
+		pk, err := signature.DeserializePublicKey(pkb)
+		if err != nil {
 			return err
 		}
+		s.PubKeys = append(s.PubKeys, pk)
 	}
 
-	return nil
-}
+	if s.M, err = source.ReadUint8(); err != nil {
+		return err
+	}
 
-//Deserialize implement Payload interface
-func (s *Sig) Deserialize(r io.Reader) error {
-	var pkvu serialize.VarUint
-	if err := pkvu.Deserialize(r); err != nil {
+	n, err = source.ReadVarUint(common.MAX_MULTI_PUBKEYS)
+	if err != nil {
 		return err
 	}
-	s.PubKeys = make([]keypair.PublicKey, pkvu.Value)
-	for i := uint64(0); i < pkvu.Value; i++ {
-		var pkvb serialize.VarBytes
-		if err := pkvb.Deserialize(r); err != nil {
-			return err
-		}
-		var err error
-		s.PubKeys[i], err = keypair.DeserializePublicKey(pkvb.Bytes)
+	s.SigData = make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		sig, err := source.ReadVarBytes(signature.MaxSignatureSize)
 		if err != nil {
 			return err
 		}
+		s.SigData = append(s.SigData, sig)
+	}
 
+	return nil
+}
+
+// Address get the address of account controlled by the public keys of signature
+// see common.AddressFromPubKey and common.AddressFromMultiPubKeys
+func (s *Sig) Address() (common.Address, error) {
+	if len(s.PubKeys) == 1 {
+		return common.AddressFromPubKey(s.PubKeys[0]), nil
 	}
+	return common.AddressFromMultiPubKeys(int(s.M), s.PubKeys)
+}
 
-	binary.Read(r, binary.LittleEndian, &s.M)
+type sigJSON struct {
+	PubKeys []string          `json:"pub_keys"`
+	M       uint8             `json:"m"`
+	SigData []common.HexBytes `json:"sig_data"`
+}
 
-	var sgvu serialize.VarUint
-	if err := sgvu.Deserialize(r); err != nil {
-		return err
+// MarshalJSON implement json.Marshaler
+func (s Sig) MarshalJSON() ([]byte, error) {
+	sj := &sigJSON{
+		PubKeys: signature.PublicKeysToHex(s.PubKeys),
+		M:       s.M,
+		SigData: make([]common.HexBytes, 0, len(s.SigData)),
 	}
-	s.SigData = make([][]byte, sgvu.Value)
-	for i := uint64(0); i < sgvu.Value; i++ {
-		var sgvb serialize.VarBytes
-		if err := sgvb.Deserialize(r); err != nil {
-			return err
-		}
-		s.SigData[i] = sgvb.Bytes
+	for _, sig := range s.SigData {
+		sj.SigData = append(sj.SigData, sig)
 	}
+	return json.Marshal(sj)
+}
 
+// UnmarshalJSON implement json.Unmarshaler
+func (s *Sig) UnmarshalJSON(data []byte) error {
+	var sj sigJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+	pubKeys, err := signature.PublicKeysFromHex(sj.PubKeys)
+	if err != nil {
+		return err
+	}
+	s.PubKeys = pubKeys
+	s.M = sj.M
+	s.SigData = make([][]byte, 0, len(sj.SigData))
+	for _, sig := range sj.SigData {
+		s.SigData = append(s.SigData, sig)
+	}
 	return nil
 }
@@ -1,15 +1,36 @@
 package transaction
 
 import (
-	"bytes"
 	"crypto/sha256"
-	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"io"
 
 	"github.com/mileschao/echain/common"
 	"github.com/mileschao/echain/common/serialize"
 	"github.com/mileschao/echain/core/payload"
 	stypes "github.com/mileschao/echain/smartcontract/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+const (
+	// TxVersion current version of transaction
+	TxVersion = TxVersionBookkeeperHeight
+	// TxVersionChainID the first version of transaction with ChainID, ChainID is
+	// serialized and hashed from this version. version 0 built by the constructors
+	// before carries no ChainID, the versions above TxVersion can not be decoded
+	TxVersionChainID = 0x01
+	// TxVersionBookkeeperHeight the first version of transaction whose Bookkeeper payload has Height
+	TxVersionBookkeeperHeight = payload.BookkeeperTxVersionHeight
+	// MaxTxAttributes max number of attributes in transaction
+	MaxTxAttributes = 16
+	// MaxTxSigs max number of signatures in transaction
+	MaxTxSigs = 16
+)
+
+var (
+	// ErrTxVersion transaction of version above TxVersion, whose encoding is unknown
+	ErrTxVersion = errors.New("unknown transaction version")
 )
 
 //TxType transaction type
@@ -30,10 +51,37 @@ const (
 	Vote TxType = 0x05
 )
 
+var txTypeNames = map[byte]string{
+	byte(Bookkeeper): "Bookkeeper",
+	byte(Claim):      "Claim",
+	byte(Deploy):     "Deploy",
+	byte(Invoke):     "Invoke",
+	byte(Enrollment): "Enrollment",
+	byte(Vote):       "Vote",
+}
+
+// String get the name of transaction type
+func (txType TxType) String() string {
+	return common.ByteName(byte(txType), txTypeNames)
+}
+
+// MarshalText implement encoding.TextMarshaler, transaction type is encoded as name
+func (txType TxType) MarshalText() ([]byte, error) {
+	return []byte(txType.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (txType *TxType) UnmarshalText(text []byte) error {
+	v, err := common.ParseByteName(string(text), txTypeNames)
+	*txType = TxType(v)
+	return err
+}
+
 // Transaction transaction
 type Transaction struct {
 	Version    byte
 	TxType     TxType
+	ChainID    uint32
 	Nonce      uint32
 	GasPrice   uint64
 	GasLimit   uint64
@@ -45,8 +93,8 @@ type Transaction struct {
 }
 
 // NewDeployTx returns a deploy Transaction
-func NewDeployTx(code stypes.VMCode, name, version, author, email, desp string, needStorage bool) *Transaction {
-	//TODO: check arguments
+// ErrInvalidPayload returned if the code is empty or of unknown vm type, or the fields are too long
+func NewDeployTx(code stypes.VMCode, name, version, author, email, desp string, needStorage bool) (*Transaction, error) {
 	DeployCodePayload := &payload.DeployCode{
 		Code:        code,
 		NeedStorage: needStorage,
@@ -57,144 +105,257 @@ func NewDeployTx(code stypes.VMCode, name, version, author, email, desp string,
 		Description: desp,
 	}
 
+	if !DeployCodePayload.IsValid() {
+		return nil, ErrInvalidPayload
+	}
+
 	return &Transaction{
+		Version:    TxVersion,
 		TxType:     Deploy,
 		Payload:    DeployCodePayload,
 		Attributes: nil,
-	}
+	}, nil
 }
 
 // NewInvokeTx returns an invoke Transaction
-func NewInvokeTx(vmcode stypes.VMCode) *Transaction {
-	//TODO: check arguments
+// ErrInvalidPayload returned if the code is empty or of unknown vm type
+func NewInvokeTx(vmcode stypes.VMCode) (*Transaction, error) {
 	invokeCodePayload := &payload.InvokeCode{
 		Code: vmcode,
 	}
+	if !invokeCodePayload.IsValid() {
+		return nil, ErrInvalidPayload
+	}
 
 	return &Transaction{
+		Version:    TxVersion,
 		TxType:     Invoke,
 		Payload:    invokeCodePayload,
 		Attributes: nil,
+	}, nil
+}
+
+// NewEnrollmentTx returns an enrollment Transaction paid by the candidate
+// ErrInvalidPayload returned if the public key is nil or the deposit is zero
+func NewEnrollmentTx(pubKey keypair.PublicKey, deposit uint64) (*Transaction, error) {
+	enrollmentPayload := &payload.Enrollment{
+		PubKey:  pubKey,
+		Deposit: deposit,
+	}
+	if !enrollmentPayload.IsValid() {
+		return nil, ErrInvalidPayload
+	}
+
+	return &Transaction{
+		Version:    TxVersion,
+		TxType:     Enrollment,
+		Payer:      common.AddressFromPubKey(pubKey),
+		Payload:    enrollmentPayload,
+		Attributes: nil,
+	}, nil
+}
+
+// NewClaimTx returns a claim Transaction
+// ErrInvalidPayload returned if the claims are empty, too many, nil or duplicated
+func NewClaimTx(claims []*payload.ClaimInput) (*Transaction, error) {
+	claimPayload := &payload.Claim{
+		Claims: claims,
 	}
+	if !claimPayload.IsValid() || claimPayload.HasDuplicateInput() {
+		return nil, ErrInvalidPayload
+	}
+
+	return &Transaction{
+		Version:    TxVersion,
+		TxType:     Claim,
+		Payload:    claimPayload,
+		Attributes: nil,
+	}, nil
 }
 
 //Serialize implement Payload interface
 func (tx *Transaction) Serialize(w io.Writer) error {
-	binary.Write(w, binary.LittleEndian, tx.Version)
-	binary.Write(w, binary.LittleEndian, byte(tx.TxType))
-	binary.Write(w, binary.LittleEndian, tx.Nonce)
-	binary.Write(w, binary.LittleEndian, tx.GasPrice)
-	binary.Write(w, binary.LittleEndian, tx.GasLimit)
-	if err := tx.Payer.Serialize(w); err != nil {
+	return serialize.WriteTo(w, tx)
+}
+
+// Deserialize implement the Payload interface
+// the payload is created by NewPayload according to the TxType
+func (tx *Transaction) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, tx)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (tx *Transaction) SerializeSink(sink *serialize.Sink) error {
+	if err := tx.SerializeUnsignedSink(sink); err != nil {
 		return err
 	}
-	if err := tx.Payload.Serialize(w); err != nil {
+	sink.WriteVarUint(uint64(len(tx.Sigs)))
+	for _, sig := range tx.Sigs {
+		if err := sig.SerializeSink(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeUnsigned serialize the transaction without Sigs, which is the data hashed and signed
+func (tx *Transaction) SerializeUnsigned(w io.Writer) error {
+	sink := serialize.AcquireSink()
+	defer serialize.ReleaseSink(sink)
+	if err := tx.SerializeUnsignedSink(sink); err != nil {
 		return err
 	}
+	_, err := w.Write(sink.Bytes())
+	return err
+}
 
-	var attrvu = &serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(tx.Attributes))),
-		Value:    uint64(len(tx.Attributes)),
+// SerializeUnsignedSink serialize the transaction without Sigs into sink, see SerializeUnsigned
+func (tx *Transaction) SerializeUnsignedSink(sink *serialize.Sink) error {
+	sink.WriteUint8(tx.Version)
+	sink.WriteUint8(byte(tx.TxType))
+	if tx.HasChainID() {
+		sink.WriteUint32(tx.ChainID)
 	}
-	if err := attrvu.Serialize(w); err != nil {
-		return err
+	sink.WriteUint32(tx.Nonce)
+	sink.WriteUint64(tx.GasPrice)
+	sink.WriteUint64(tx.GasLimit)
+	sink.WriteBytes(tx.Payer[:])
+	if tx.Payload == nil {
+		return ErrNilPayload
+	}
+	switch pl := tx.Payload.(type) {
+	case payload.VersionedPayload:
+		if err := pl.SerializeVersion(sink, tx.Version); err != nil {
+			return err
+		}
+	case serialize.SinkSerializable:
+		if err := pl.SerializeSink(sink); err != nil {
+			return err
+		}
+	default:
+		if err := pl.Serialize(sink); err != nil {
+			return err
+		}
 	}
+	sink.WriteVarUint(uint64(len(tx.Attributes)))
 	for _, attr := range tx.Attributes {
-		if err := attr.Serialize(w); err != nil {
+		if err := attr.SerializeSink(sink); err != nil {
 			return err
 		}
 	}
-	var svu = &serialize.VarUint{
-		UintType: serialize.GetUintTypeByValue(uint64(len(tx.Sigs))),
-		Value:    uint64(len(tx.Sigs)),
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+// the payload is created by NewPayload according to the TxType
+func (tx *Transaction) DeserializeSource(source *serialize.Source) error {
+	var err error
+	if tx.Version, err = source.ReadUint8(); err != nil {
+		return err
 	}
-	if err := svu.Serialize(w); err != nil {
+	if tx.Version > TxVersion {
+		return ErrTxVersion
+	}
+	txType, err := source.ReadUint8()
+	if err != nil {
 		return err
 	}
-	for _, sig := range tx.Sigs {
-		if err := sig.Serialize(w); err != nil {
+	tx.TxType = TxType(txType)
+	tx.ChainID = 0
+	if tx.HasChainID() {
+		if tx.ChainID, err = source.ReadUint32(); err != nil {
 			return err
 		}
 	}
-	return nil
-}
-
-// Deserialize implement the Payload interface
-func (tx *Transaction) Deserialize(r io.Reader) error {
-	binary.Read(r, binary.LittleEndian, &tx.Version)
-	binary.Read(r, binary.LittleEndian, &tx.TxType)
-	binary.Read(r, binary.LittleEndian, &tx.Nonce)
-	binary.Read(r, binary.LittleEndian, &tx.GasPrice)
-	binary.Read(r, binary.LittleEndian, &tx.GasLimit)
-	if err := tx.Payer.Deserialize(r); err != nil {
+	if tx.Nonce, err = source.ReadUint32(); err != nil {
+		return err
+	}
+	if tx.GasPrice, err = source.ReadUint64(); err != nil {
 		return err
 	}
-	if err := tx.Payload.Deserialize(r); err != nil {
+	if tx.GasLimit, err = source.ReadUint64(); err != nil {
 		return err
 	}
-	var attrvu serialize.VarUint
-	if err := attrvu.Deserialize(r); err != nil {
+	if err := source.ReadFull(tx.Payer[:]); err != nil {
 		return err
 	}
-	tx.Attributes = make([]*TxAttribute, 0, attrvu.Value)
-	for i := uint64(0); i < attrvu.Value; i++ {
+	pl, err := NewPayload(tx.TxType)
+	if err != nil {
+		return err
+	}
+	switch pl := pl.(type) {
+	case payload.VersionedPayload:
+		if err := pl.DeserializeVersion(source, tx.Version); err != nil {
+			return err
+		}
+	case serialize.SinkSerializable:
+		if err := pl.DeserializeSource(source); err != nil {
+			return err
+		}
+	default:
+		if err := pl.Deserialize(source); err != nil {
+			return err
+		}
+	}
+	tx.Payload = pl
+	n, err := source.ReadVarUint(MaxTxAttributes)
+	if err != nil {
+		return err
+	}
+	tx.Attributes = make([]*TxAttribute, 0, n)
+	for i := uint64(0); i < n; i++ {
 		var attr TxAttribute
-		if err := attr.Deserialize(r); err != nil {
+		if err := attr.DeserializeSource(source); err != nil {
 			return err
 		}
 		tx.Attributes = append(tx.Attributes, &attr)
 	}
 
-	var sigvu serialize.VarUint
-	if err := attrvu.Deserialize(r); err != nil {
+	n, err = source.ReadVarUint(MaxTxSigs)
+	if err != nil {
 		return err
 	}
-	tx.Sigs = make([]*Sig, 0, sigvu.Value)
-	for i := uint64(0); i < sigvu.Value; i++ {
+	tx.Sigs = make([]*Sig, 0, n)
+	for i := uint64(0); i < n; i++ {
 		var sig Sig
-		if err := sig.Deserialize(r); err != nil {
+		if err := sig.DeserializeSource(source); err != nil {
 			return err
 		}
 		tx.Sigs = append(tx.Sigs, &sig)
 	}
+	tx.hash = nil
 	return nil
 }
 
 // Hash get the transaction's hash value
 func (tx *Transaction) Hash() common.Uint256 {
 	if tx.hash == nil {
-		w := new(bytes.Buffer)
-		binary.Write(w, binary.LittleEndian, tx.Version)
-		binary.Write(w, binary.LittleEndian, byte(tx.TxType))
-		binary.Write(w, binary.LittleEndian, tx.Nonce)
-		binary.Write(w, binary.LittleEndian, tx.GasPrice)
-		binary.Write(w, binary.LittleEndian, tx.GasLimit)
-		if err := tx.Payer.Serialize(w); err != nil {
-			return common.UINT256_EMPTY
-		}
-		if err := tx.Payload.Serialize(w); err != nil {
+		sink := serialize.AcquireSink()
+		defer serialize.ReleaseSink(sink)
+		if err := tx.SerializeUnsignedSink(sink); err != nil {
 			return common.UINT256_EMPTY
 		}
-		var attrvu = &serialize.VarUint{
-			UintType: serialize.GetUintTypeByValue(uint64(len(tx.Attributes))),
-			Value:    uint64(len(tx.Attributes)),
-		}
-		if err := attrvu.Serialize(w); err != nil {
-			return common.UINT256_EMPTY
-		}
-		for _, attr := range tx.Attributes {
-			if err := attr.Serialize(w); err != nil {
-				return common.UINT256_EMPTY
-			}
-		}
-
-		temp := sha256.Sum256(w.Bytes())
+		temp := sha256.Sum256(sink.Bytes())
 		f := common.Uint256(sha256.Sum256(temp[:]))
 		tx.hash = &f
 	}
 	return *tx.hash
 }
 
+// HasChainID whether the ChainID is serialized and hashed by the version of transaction
+func (tx *Transaction) HasChainID() bool {
+	return tx.Version >= TxVersionChainID
+}
+
+// SetChainID bind the transaction to the chain, which should be done before signing
+func (tx *Transaction) SetChainID(chainID uint32) {
+	if !tx.HasChainID() {
+		tx.Version = TxVersion
+	}
+	tx.ChainID = chainID
+	tx.hash = nil
+}
+
 // SetHash set hash value of the transaction
 func (tx *Transaction) SetHash(hash common.Uint256) {
 	tx.hash = &hash
@@ -202,7 +363,74 @@ func (tx *Transaction) SetHash(hash common.Uint256) {
 
 // Bytes get byte array of transaction
 func (tx *Transaction) Bytes() []byte {
-	b := new(bytes.Buffer)
-	tx.Serialize(b)
-	return b.Bytes()
+	b, _ := serialize.ToBytes(tx)
+	return b
+}
+
+type transactionJSON struct {
+	Version    byte            `json:"version"`
+	TxType     TxType          `json:"tx_type"`
+	ChainID    uint32          `json:"chain_id"`
+	Nonce      uint32          `json:"nonce"`
+	GasPrice   uint64          `json:"gas_price"`
+	GasLimit   uint64          `json:"gas_limit"`
+	Payer      common.Address  `json:"payer"`
+	Payload    json.RawMessage `json:"payload"`
+	Attributes []*TxAttribute  `json:"attributes"`
+	Sigs       []*Sig          `json:"sigs"`
+	Hash       common.Uint256  `json:"hash"`
+}
+
+// MarshalJSON implement json.Marshaler
+// hash is informative only, which is ignored by UnmarshalJSON
+func (tx Transaction) MarshalJSON() ([]byte, error) {
+	pl, err := json.Marshal(tx.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&transactionJSON{
+		Version:    tx.Version,
+		TxType:     tx.TxType,
+		ChainID:    tx.ChainID,
+		Nonce:      tx.Nonce,
+		GasPrice:   tx.GasPrice,
+		GasLimit:   tx.GasLimit,
+		Payer:      tx.Payer,
+		Payload:    pl,
+		Attributes: tx.Attributes,
+		Sigs:       tx.Sigs,
+		Hash:       tx.Hash(),
+	})
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+// the payload is created by NewPayload according to the TxType
+func (tx *Transaction) UnmarshalJSON(data []byte) error {
+	var tj transactionJSON
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return err
+	}
+	var pl payload.Payload
+	if len(tj.Payload) > 0 && string(tj.Payload) != "null" {
+		var err error
+		if pl, err = NewPayload(tj.TxType); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(tj.Payload, pl); err != nil {
+			return err
+		}
+	}
+	*tx = Transaction{
+		Version:    tj.Version,
+		TxType:     tj.TxType,
+		ChainID:    tj.ChainID,
+		Nonce:      tj.Nonce,
+		GasPrice:   tj.GasPrice,
+		GasLimit:   tj.GasLimit,
+		Payer:      tj.Payer,
+		Payload:    pl,
+		Attributes: tj.Attributes,
+		Sigs:       tj.Sigs,
+	}
+	return nil
 }
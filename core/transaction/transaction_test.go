@@ -1,16 +1,103 @@
 package transaction
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/smartcontract/types"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
+func testInvokeTx(tb testing.TB, code []byte) *Transaction {
+	tx, err := NewInvokeTx(types.VMCode{VMType: types.NEOVM, Code: code})
+	if err != nil {
+		tb.Fatalf("new invoke tx: %s", err)
+	}
+	return tx
+}
+
+func testDeployTx(tb testing.TB, vmType types.VMType) *Transaction {
+	tx, err := NewDeployTx(types.VMCode{VMType: vmType, Code: []byte{0xFF}}, "n", "v", "a", "e", "d", true)
+	if err != nil {
+		tb.Fatalf("new deploy tx: %s", err)
+	}
+	return tx
+}
+
+func testEnrollmentTx(tb testing.TB, pk keypair.PublicKey) *Transaction {
+	tx, err := NewEnrollmentTx(pk, 1000)
+	if err != nil {
+		tb.Fatalf("new enrollment tx: %s", err)
+	}
+	return tx
+}
+
+func testClaimTx(tb testing.TB) *Transaction {
+	tx, err := NewClaimTx([]*payload.ClaimInput{{PrevHash: common.Uint256{0xFF}, PrevIndex: 1}})
+	if err != nil {
+		tb.Fatalf("new claim tx: %s", err)
+	}
+	return tx
+}
+
+func TestTxConstructorArguments(t *testing.T) {
+	code := types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}
+	long := strings.Repeat("x", payload.MaxDeployFieldSize+1)
+	invalid := []types.VMCode{
+		{VMType: types.NEOVM},
+		{VMType: 0x01, Code: []byte{0xFF}},
+	}
+	for _, c := range invalid {
+		if _, err := NewInvokeTx(c); err != ErrInvalidPayload {
+			t.Errorf("new invoke tx with code %v: %v", c, err)
+		}
+		if _, err := NewDeployTx(c, "n", "v", "a", "e", "d", true); err != ErrInvalidPayload {
+			t.Errorf("new deploy tx with code %v: %v", c, err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		fields := []string{"n", "v", "a", "e"}
+		fields[i] = long
+		if _, err := NewDeployTx(code, fields[0], fields[1], fields[2], fields[3], "d", true); err != ErrInvalidPayload {
+			t.Errorf("new deploy tx with field %d too long: %v", i, err)
+		}
+	}
+	desc := strings.Repeat("x", payload.MaxDeployDescriptionSize+1)
+	if _, err := NewDeployTx(code, "n", "v", "a", "e", desc, true); err != ErrInvalidPayload {
+		t.Errorf("new deploy tx with description too long: %v", err)
+	}
+
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key pair: %s", err)
+	}
+	if _, err := NewEnrollmentTx(nil, 1000); err != ErrInvalidPayload {
+		t.Errorf("new enrollment tx without public key: %v", err)
+	}
+	if _, err := NewEnrollmentTx(pk, 0); err != ErrInvalidPayload {
+		t.Errorf("new enrollment tx without deposit: %v", err)
+	}
+	input := &payload.ClaimInput{PrevHash: common.Uint256{0xFF}}
+	claims := [][]*payload.ClaimInput{nil, {}, {nil}, {input, input}}
+	for _, c := range claims {
+		if _, err := NewClaimTx(c); err != ErrInvalidPayload {
+			t.Errorf("new claim tx with claims %v: %v", c, err)
+		}
+	}
+}
+
 func TestTxSerialize(t *testing.T) {
 	var tx Transaction
-	tx.Version = 0xFF
 	tx.TxType = Deploy
 	tx.Nonce = 0xFFFF
 	tx.GasPrice = 0x01
@@ -21,7 +108,10 @@ func TestTxSerialize(t *testing.T) {
 		0xFF, 0xFE, 0xFD, 0xFC, 0xFB,
 		0xFF, 0xFE, 0xFD, 0xFC, 0xFB,
 	}
-	tx.Payload = nil
+	tx.Payload = &payload.DeployCode{
+		Code: types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}},
+		Name: "name",
+	}
 	var txAttr = &TxAttribute{
 		Usage: Nonce,
 		Data:  []byte{0xFF},
@@ -50,8 +140,363 @@ func TestTxSerialize(t *testing.T) {
 		t.Errorf("tx deserialize: %s", err)
 	}
 
-	if tx2.Sigs[0].M != tx.Sigs[0].M {
-		t.Errorf("tx deserialize")
+	if len(tx2.Sigs) != 1 || tx2.Sigs[0].M != tx.Sigs[0].M {
+		t.Fatalf("tx deserialize")
 	}
+	if tx2.Hash() != tx.Hash() {
+		t.Errorf("tx deserialize:\n%X\n%X", tx2.Hash(), tx.Hash())
+	}
+	if pl, ok := tx2.Payload.(*payload.DeployCode); !ok || pl.Name != "name" {
+		t.Errorf("tx deserialize payload: %v", tx2.Payload)
+	}
+
+	tx.Payload = nil
+	if err := tx.Serialize(new(bytes.Buffer)); err != ErrNilPayload {
+		t.Errorf("tx serialize without payload: %v", err)
+	}
+}
+
+func TestTxDeserializePayload(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate public key:%s", err)
+	}
+	txs := []*Transaction{
+		{TxType: Bookkeeper, Payload: &payload.Bookkeeper{
+			PubKey: pk,
+			Action: payload.BookkeeperActionADD,
+			Cert:   []byte{0xFF},
+			Issuer: pk,
+		}},
+		testDeployTx(t, types.NEOVM),
+		testInvokeTx(t, []byte{0xFF}),
+		{TxType: Vote, Payload: &payload.Vote{PubKeys: []keypair.PublicKey{pk}}},
+		testEnrollmentTx(t, pk),
+		testClaimTx(t),
+	}
+	for _, tx := range txs {
+		var tx2 Transaction
+		if err := tx2.Deserialize(bytes.NewReader(tx.Bytes())); err != nil {
+			t.Errorf("tx 0x%02x deserialize: %s", byte(tx.TxType), err)
+			continue
+		}
+		if tx2.Hash() != tx.Hash() {
+			t.Errorf("tx 0x%02x deserialize:\n%X\n%X", byte(tx.TxType), tx2.Hash(), tx.Hash())
+		}
+	}
+
+	raw := testInvokeTx(t, []byte{0xFF}).Bytes()
+	raw[1] = 0xEE
+	var tx Transaction
+	if err := tx.Deserialize(bytes.NewReader(raw)); err == nil {
+		t.Errorf("tx deserialize with unknown type")
+	}
+	RegisterPayload(0xEE, func() payload.Payload { return new(payload.InvokeCode) })
+	defer func() {
+		payloadLock.Lock()
+		delete(payloadCreators, 0xEE)
+		payloadLock.Unlock()
+	}()
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Errorf("tx deserialize with registered type: %s", err)
+	}
+}
+
+// plainPayload payload of external tx type which only implements Serializable
+type plainPayload struct {
+	Data []byte
+}
+
+func (pl *plainPayload) Serialize(w io.Writer) error {
+	vb := serialize.VarBytes{Len: uint64(len(pl.Data)), Bytes: pl.Data}
+	return vb.Serialize(w)
+}
+
+func (pl *plainPayload) Deserialize(r io.Reader) error {
+	var err error
+	pl.Data, err = serialize.ReadVarBytes(r, serialize.MaxVarBytesLen)
+	return err
+}
 
+func TestTxPlainPayload(t *testing.T) {
+	RegisterPayload(0xEF, func() payload.Payload { return new(plainPayload) })
+	defer func() {
+		payloadLock.Lock()
+		delete(payloadCreators, 0xEF)
+		payloadLock.Unlock()
+	}()
+	tx := &Transaction{TxType: 0xEF, Payload: &plainPayload{Data: []byte("plain")}}
+	raw := tx.Bytes()
+	var tx2 Transaction
+	if err := serialize.Decode(raw, &tx2); err != nil {
+		t.Fatalf("tx deserialize with plain payload: %s", err)
+	}
+	if pl, ok := tx2.Payload.(*plainPayload); !ok || string(pl.Data) != "plain" {
+		t.Errorf("tx deserialize plain payload: %v", tx2.Payload)
+	}
+	if tx2.Hash() != tx.Hash() {
+		t.Errorf("tx deserialize with plain payload:\n%X\n%X", tx2.Hash(), tx.Hash())
+	}
+}
+
+func TestTxDeserializeStream(t *testing.T) {
+	txs := []*Transaction{
+		testInvokeTx(t, []byte{0xFF}),
+		testClaimTx(t),
+	}
+	var readers []io.Reader
+	for _, tx := range txs {
+		readers = append(readers, bytes.NewReader(tx.Bytes()))
+	}
+	r := bufio.NewReader(io.MultiReader(readers...))
+	for i, tx := range txs {
+		var tx2 Transaction
+		if err := tx2.Deserialize(r); err != nil {
+			t.Fatalf("tx %d deserialize from stream: %s", i, err)
+		}
+		if tx2.Hash() != tx.Hash() {
+			t.Errorf("tx %d deserialize from stream:\n%X\n%X", i, tx2.Hash(), tx.Hash())
+		}
+	}
+}
+
+func TestTxChainID(t *testing.T) {
+	tx := testInvokeTx(t, []byte{0xFF})
+	if tx.Version != TxVersion || !tx.HasChainID() {
+		t.Errorf("tx version of constructor: %d", tx.Version)
+	}
+	tx.Version = 0
+	legacy := tx.Hash()
+	tx.hash = nil
+	tx.SetChainID(1)
+	if tx.Version != TxVersion || tx.Hash() == legacy {
+		t.Errorf("tx with chain id: %d", tx.Version)
+	}
+	hash := tx.Hash()
+	tx.SetChainID(2)
+	if tx.Hash() == hash {
+		t.Errorf("tx hash of other chain")
+	}
+
+	var tx2 Transaction
+	if err := tx2.Deserialize(bytes.NewReader(tx.Bytes())); err != nil {
+		t.Fatalf("tx deserialize: %s", err)
+	}
+	if tx2.ChainID != 2 || tx2.Hash() != tx.Hash() {
+		t.Errorf("tx deserialize chain id: %d", tx2.ChainID)
+	}
+
+	tx.Version = 0
+	tx.hash = nil
+	if tx.Hash() != legacy {
+		t.Errorf("legacy tx hash with chain id")
+	}
+	tx.Version = TxVersion + 1
+	var tx3 Transaction
+	if err := tx3.Deserialize(bytes.NewReader(tx.Bytes())); err != ErrTxVersion {
+		t.Errorf("unknown tx version deserialize: %v", err)
+	}
+}
+
+func TestTxBookkeeperHeight(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key pair: %s", err)
+	}
+	bk := &payload.Bookkeeper{
+		PubKey: pk,
+		Action: payload.BookkeeperActionADD,
+		Height: 5,
+		Cert:   []byte{0xFF},
+		Issuer: pk,
+	}
+	// Bookkeeper payload of the versions before TxVersionBookkeeperHeight has no Height
+	legacy := serialize.NewSink(nil)
+	legacy.WriteVarBytes(keypair.SerializePublicKey(pk))
+	legacy.WriteUint8(byte(bk.Action))
+	legacy.WriteVarBytes(bk.Cert)
+	legacy.WriteVarBytes(keypair.SerializePublicKey(pk))
+	for _, version := range []byte{0, TxVersionChainID} {
+		tx := &Transaction{Version: version, TxType: Bookkeeper, Payload: bk}
+		raw := tx.Bytes()
+		if !bytes.Contains(raw, legacy.Bytes()) {
+			t.Errorf("bookkeeper payload of tx version %d is not legacy encoded", version)
+		}
+		var tx2 Transaction
+		if err := tx2.Deserialize(bytes.NewReader(raw)); err != nil {
+			t.Fatalf("tx version %d deserialize: %s", version, err)
+		}
+		if tx2.Payload.(*payload.Bookkeeper).Height != 0 || !bytes.Equal(tx2.Bytes(), raw) {
+			t.Errorf("tx version %d deserialize legacy bookkeeper payload", version)
+		}
+	}
+
+	tx := &Transaction{Version: TxVersion, TxType: Bookkeeper, Payload: bk}
+	var tx2 Transaction
+	if err := tx2.Deserialize(bytes.NewReader(tx.Bytes())); err != nil {
+		t.Fatalf("tx deserialize: %s", err)
+	}
+	if tx2.Payload.(*payload.Bookkeeper).Height != 5 || tx2.Hash() != tx.Hash() {
+		t.Errorf("tx deserialize bookkeeper height")
+	}
+}
+
+func TestTxDeserializeLimit(t *testing.T) {
+	tx := testInvokeTx(t, []byte{0xFF})
+	for i := 0; i <= MaxTxAttributes; i++ {
+		attr := NewTxAttribute(Nonce, []byte{byte(i)})
+		tx.Attributes = append(tx.Attributes, &attr)
+	}
+	var tx2 Transaction
+	if err := tx2.Deserialize(bytes.NewReader(tx.Bytes())); err != serialize.ErrExceedLimit {
+		t.Errorf("expect ErrExceedLimit, got %v", err)
+	}
+
+	raw := testInvokeTx(t, []byte{0xFF}).Bytes()
+	for i := 0; i < len(raw); i++ {
+		if err := tx2.Deserialize(bytes.NewReader(raw[:i])); err == nil {
+			t.Errorf("tx deserialize truncated at %d", i)
+		}
+	}
+}
+
+func BenchmarkTxHash(b *testing.B) {
+	tx := testInvokeTx(b, make([]byte, 64))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tx.hash = nil
+		tx.Hash()
+	}
+}
+
+func TestTxJSON(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate public key:%s", err)
+	}
+	attr := NewTxAttribute(Description, []byte("test"))
+	invoke := testInvokeTx(t, []byte{0xFF})
+	invoke.SetChainID(1)
+	invoke.Attributes = []*TxAttribute{&attr}
+	invoke.Sigs = []*Sig{{PubKeys: []keypair.PublicKey{pk}, M: 1, SigData: [][]byte{{0xFF}}}}
+	txs := []*Transaction{
+		invoke,
+		{TxType: Bookkeeper, Payload: &payload.Bookkeeper{
+			PubKey: pk,
+			Action: payload.BookkeeperActionSUB,
+			Cert:   []byte{0xFF},
+			Issuer: pk,
+		}},
+		testDeployTx(t, types.WASMVM),
+		{TxType: Vote, Payload: &payload.Vote{PubKeys: []keypair.PublicKey{pk}, Account: common.Address{0xFF}}},
+		testEnrollmentTx(t, pk),
+		testClaimTx(t),
+	}
+	for _, tx := range txs {
+		data, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx %s marshal json: %s", tx.TxType, err)
+		}
+		var tx2 Transaction
+		if err := json.Unmarshal(data, &tx2); err != nil {
+			t.Fatalf("tx %s unmarshal json: %s\n%s", tx.TxType, err, data)
+		}
+		if !bytes.Equal(tx2.Bytes(), tx.Bytes()) {
+			t.Errorf("tx %s json round trip:\n%s", tx.TxType, data)
+		}
+		data2, _ := json.Marshal(&tx2)
+		if !bytes.Equal(data2, data) {
+			t.Errorf("tx %s json round trip:\n%s\n%s", tx.TxType, data, data2)
+		}
+	}
+
+	data, _ := json.Marshal(invoke)
+	for _, field := range []string{`"tx_type":"Invoke"`, `"vm_type":"NEOVM"`, `"usage":"Description"`,
+		`"payer":"` + invoke.Payer.Base58() + `"`, `"pub_keys":["` + signature.PublicKeyToHex(pk) + `"]`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("tx json without %s:\n%s", field, data)
+		}
+	}
+	var tx Transaction
+	if err := json.Unmarshal([]byte(`{"tx_type":"Unknown"}`), &tx); err == nil {
+		t.Errorf("tx unmarshal json with unknown type")
+	}
+}
+
+func TestTxSignedFields(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate public key:%s", err)
+	}
+	newTx := func() *Transaction {
+		attr := NewTxAttribute(Nonce, []byte{0xFF})
+		tx := testInvokeTx(t, []byte{0xFF})
+		tx.SetChainID(1)
+		tx.Nonce = 1
+		tx.GasPrice = 1
+		tx.GasLimit = 1
+		tx.Payer = common.Address{0x01}
+		tx.Attributes = []*TxAttribute{&attr}
+		tx.Sigs = []*Sig{{PubKeys: []keypair.PublicKey{pk}, M: 1, SigData: [][]byte{{0xFF}}}}
+		return tx
+	}
+	signed := map[string]func(tx *Transaction){
+		"Version":  func(tx *Transaction) { tx.Version++ },
+		"TxType":   func(tx *Transaction) { tx.TxType = Deploy },
+		"ChainID":  func(tx *Transaction) { tx.ChainID++ },
+		"Nonce":    func(tx *Transaction) { tx.Nonce++ },
+		"GasPrice": func(tx *Transaction) { tx.GasPrice++ },
+		"GasLimit": func(tx *Transaction) { tx.GasLimit++ },
+		"Payer":    func(tx *Transaction) { tx.Payer[0]++ },
+		"Payload": func(tx *Transaction) {
+			tx.Payload = &payload.InvokeCode{Code: types.VMCode{VMType: types.NEOVM, Code: []byte{0xFE}}}
+		},
+		"Attributes": func(tx *Transaction) { tx.Attributes[0].Data = []byte{0xFE} },
+	}
+	unsigned := map[string]func(tx *Transaction){
+		"Sigs": func(tx *Transaction) { tx.Sigs[0].SigData = [][]byte{{0xFE}} },
+	}
+	typ := reflect.TypeOf(Transaction{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if typ.Field(i).PkgPath != "" {
+			continue
+		}
+		if signed[name] == nil && unsigned[name] == nil {
+			t.Errorf("tx field %s is neither signed nor unsigned", name)
+		}
+	}
+
+	tx := newTx()
+	buf := new(bytes.Buffer)
+	if err := tx.SerializeUnsigned(buf); err != nil {
+		t.Fatalf("tx serialize unsigned: %s", err)
+	}
+	tmp := sha256.Sum256(buf.Bytes())
+	if tx.Hash() != common.Uint256(sha256.Sum256(tmp[:])) {
+		t.Errorf("tx hash is not of unsigned data")
+	}
+	if !bytes.HasPrefix(tx.Bytes(), buf.Bytes()) {
+		t.Errorf("tx serialize does not start with unsigned data")
+	}
+	for name, mutate := range signed {
+		tx2 := newTx()
+		mutate(tx2)
+		if tx2.Hash() == tx.Hash() {
+			t.Errorf("tx hash unchanged with signed field %s", name)
+		}
+	}
+	for name, mutate := range unsigned {
+		tx2 := newTx()
+		mutate(tx2)
+		if tx2.Hash() != tx.Hash() {
+			t.Errorf("tx hash changed with unsigned field %s", name)
+		}
+		if bytes.Equal(tx2.Bytes(), tx.Bytes()) {
+			t.Errorf("tx field %s is not serialized", name)
+		}
+	}
+	if err := (&Transaction{}).SerializeUnsigned(buf); err != ErrNilPayload {
+		t.Errorf("tx serialize unsigned without payload: %v", err)
+	}
 }
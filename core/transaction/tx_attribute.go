@@ -1,12 +1,12 @@
 package transaction
 
 import (
-	"bytes"
-	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"io"
 	"reflect"
 
+	"github.com/mileschao/echain/common"
 	"github.com/mileschao/echain/common/serialize"
 )
 
@@ -30,7 +30,32 @@ const (
 	Description TxAttrUsage = 0x90
 )
 
-func (txattr TxAttrUsage) isValid() bool {
+var txAttrUsageNames = map[byte]string{
+	byte(Nonce):          "Nonce",
+	byte(Script):         "Script",
+	byte(DescriptionURL): "DescriptionURL",
+	byte(Description):    "Description",
+}
+
+// String get the name of usage
+func (txattr TxAttrUsage) String() string {
+	return common.ByteName(byte(txattr), txAttrUsageNames)
+}
+
+// MarshalText implement encoding.TextMarshaler, usage is encoded as name
+func (txattr TxAttrUsage) MarshalText() ([]byte, error) {
+	return []byte(txattr.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (txattr *TxAttrUsage) UnmarshalText(text []byte) error {
+	v, err := common.ParseByteName(string(text), txAttrUsageNames)
+	*txattr = TxAttrUsage(v)
+	return err
+}
+
+// IsValid check whether the usage is one of Nonce, Script, DescriptionURL and Description
+func (txattr TxAttrUsage) IsValid() bool {
 	if txattr != Nonce &&
 		txattr != Script &&
 		txattr != DescriptionURL &&
@@ -65,34 +90,64 @@ func NewTxAttribute(usage TxAttrUsage, data []byte) TxAttribute {
 
 //Serialize implement Serializable interface
 func (tx *TxAttribute) Serialize(w io.Writer) error {
+	return serialize.WriteTo(w, tx)
+}
 
-	if err := binary.Write(w, binary.LittleEndian, byte(tx.Usage)); err != nil {
-		return err
-	}
-	if !tx.Usage.isValid() {
+//Deserialize implement Serializable interface
+func (tx *TxAttribute) Deserialize(r io.Reader) error {
+	return serialize.ReadFrom(r, tx)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (tx *TxAttribute) SerializeSink(sink *serialize.Sink) error {
+	if !tx.Usage.IsValid() {
 		return ErrUnSupportUsageType
 	}
-	var vbd = &serialize.VarBytes{
-		Len:   uint64(len(tx.Data)),
-		Bytes: tx.Data,
-	}
-
-	return vbd.Serialize(w)
+	sink.WriteUint8(byte(tx.Usage))
+	sink.WriteVarBytes(tx.Data)
+	return nil
 }
 
-//Deserialize implement Serializable interface
-func (tx *TxAttribute) Deserialize(r io.Reader) error {
-	binary.Read(r, binary.LittleEndian, &tx.Usage)
-	if !tx.Usage.isValid() {
+// DeserializeSource implement SinkSerializable interface
+// Size is set by NewTxAttribute, the same as UnmarshalJSON
+func (tx *TxAttribute) DeserializeSource(source *serialize.Source) error {
+	usage, err := source.ReadUint8()
+	if err != nil {
+		return err
+	}
+	if !TxAttrUsage(usage).IsValid() {
 		return ErrUnSupportUsageType
 	}
-	var vbd serialize.VarBytes
-	return vbd.Deserialize(r)
+	data, err := source.ReadVarBytes(serialize.MaxVarBytesLen)
+	if err != nil {
+		return err
+	}
+	*tx = NewTxAttribute(TxAttrUsage(usage), data)
+	return nil
 }
 
 //Bytes get the byte array of TxAttribute
 func (tx *TxAttribute) Bytes() []byte {
-	bf := new(bytes.Buffer)
-	tx.Serialize(bf)
-	return bf.Bytes()
+	b, _ := serialize.ToBytes(tx)
+	return b
+}
+
+type txAttributeJSON struct {
+	Usage TxAttrUsage     `json:"usage"`
+	Data  common.HexBytes `json:"data"`
+}
+
+// MarshalJSON implement json.Marshaler
+func (tx TxAttribute) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&txAttributeJSON{Usage: tx.Usage, Data: tx.Data})
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+func (tx *TxAttribute) UnmarshalJSON(data []byte) error {
+	var aj txAttributeJSON
+	if err := json.Unmarshal(data, &aj); err != nil {
+		return err
+	}
+	*tx = NewTxAttribute(aj.Usage, aj.Data)
+	return nil
 }
@@ -2,6 +2,8 @@ package transaction
 
 import (
 	"bytes"
+	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -16,4 +18,27 @@ func TestTxAttrSerialze(t *testing.T) {
 	if err := txAttr2.Deserialize(buf); err != nil {
 		t.Errorf("tx attribute deserialize: %s", err)
 	}
+	if !reflect.DeepEqual(txAttr, txAttr2) {
+		t.Errorf("tx attribute deserialize: %+v", txAttr2)
+	}
+}
+
+func TestTxAttrJSON(t *testing.T) {
+	for _, usage := range []TxAttrUsage{Nonce, Script, DescriptionURL, Description} {
+		txAttr := NewTxAttribute(usage, []byte{0xFF, 0xFE})
+		data, err := json.Marshal(txAttr)
+		if err != nil {
+			t.Fatalf("tx attribute marshal: %s", err)
+		}
+		var fromJSON, fromBinary TxAttribute
+		if err := json.Unmarshal(data, &fromJSON); err != nil {
+			t.Fatalf("tx attribute unmarshal: %s", err)
+		}
+		if err := fromBinary.Deserialize(bytes.NewReader(txAttr.Bytes())); err != nil {
+			t.Fatalf("tx attribute deserialize: %s", err)
+		}
+		if !reflect.DeepEqual(fromJSON, fromBinary) || !reflect.DeepEqual(fromJSON, txAttr) {
+			t.Errorf("tx attribute %s from json %+v, from binary %+v", usage, fromJSON, fromBinary)
+		}
+	}
 }
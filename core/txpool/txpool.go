@@ -0,0 +1,146 @@
+package txpool
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/errors"
+)
+
+// TxChecker check whether the transaction has been persisted, i.e. ledger.BlockStore
+type TxChecker interface {
+	ContainTransaction(txHash common.Uint256) (bool, error)
+}
+
+// txEntry transaction in pool with its arrival sequence
+type txEntry struct {
+	tx  *transaction.Transaction
+	seq uint64
+}
+
+// less entry with lower gas price, or later arrival with the same gas price
+func (e *txEntry) less(o *txEntry) bool {
+	if e.tx.GasPrice != o.tx.GasPrice {
+		return e.tx.GasPrice < o.tx.GasPrice
+	}
+	return e.seq > o.seq
+}
+
+// TxPool concurrent-safe transaction pool
+// transactions are ordered by GasPrice, the earlier arrival first with the same GasPrice.
+// when the pool is full, the transaction with lowest GasPrice is evicted
+// if the new one offers higher GasPrice
+type TxPool struct {
+	sync.RWMutex
+	capacity int
+	checker  TxChecker
+	seq      uint64
+	txs      map[common.Uint256]*txEntry
+}
+
+// NewTxPool create an new transaction pool with capacity
+// checker is used to reject persisted transactions, ignored if nil
+func NewTxPool(capacity int, checker TxChecker) *TxPool {
+	return &TxPool{
+		capacity: capacity,
+		checker:  checker,
+		txs:      make(map[common.Uint256]*txEntry),
+	}
+}
+
+// AddTransaction verify and add transaction into pool
+func (tp *TxPool) AddTransaction(tx *transaction.Transaction) error {
+	hash := tx.Hash()
+	if tp.checker != nil {
+		exist, err := tp.checker.ContainTransaction(hash)
+		if err != nil {
+			return errors.NewDetailErr(err, errors.ErrUnknown, "check transaction in ledger")
+		}
+		if exist {
+			return errors.NewDetailErr(errors.ErrDuplicatedTx, errors.ErrDuplicatedTx, fmt.Sprintf("transaction %x", hash))
+		}
+	}
+	if err := transaction.VerifyTransactionSignatures(tx); err != nil {
+		return errors.NewDetailErr(err, errors.ErrVerifySignature, fmt.Sprintf("transaction %x", hash))
+	}
+
+	tp.Lock()
+	defer tp.Unlock()
+	if _, ok := tp.txs[hash]; ok {
+		return errors.NewDetailErr(errors.ErrTxHashDuplicate, errors.ErrTxHashDuplicate, fmt.Sprintf("transaction %x", hash))
+	}
+	entry := &txEntry{tx: tx, seq: tp.seq}
+	if len(tp.txs) >= tp.capacity {
+		lowest := tp.lowest()
+		if lowest == nil || !lowest.less(entry) {
+			return errors.NewDetailErr(errors.ErrTxPoolFull, errors.ErrTxPoolFull, fmt.Sprintf("transaction %x", hash))
+		}
+		delete(tp.txs, lowest.tx.Hash())
+	}
+	tp.seq++
+	tp.txs[hash] = entry
+	return nil
+}
+
+// lowest get the entry that to be evicted first
+func (tp *TxPool) lowest() *txEntry {
+	var lowest *txEntry
+	for _, e := range tp.txs {
+		if lowest == nil || e.less(lowest) {
+			lowest = e
+		}
+	}
+	return lowest
+}
+
+// GetTransaction get transaction in pool by hash, nil if not exists
+func (tp *TxPool) GetTransaction(hash common.Uint256) *transaction.Transaction {
+	tp.RLock()
+	defer tp.RUnlock()
+	if e, ok := tp.txs[hash]; ok {
+		return e.tx
+	}
+	return nil
+}
+
+// Size get the number of transactions in pool
+func (tp *TxPool) Size() int {
+	tp.RLock()
+	defer tp.RUnlock()
+	return len(tp.txs)
+}
+
+// GetBatch get at most n transactions with highest GasPrice for block building
+// the transactions are still in pool until RemoveTransactions
+func (tp *TxPool) GetBatch(n int) []*transaction.Transaction {
+	tp.RLock()
+	entries := make([]*txEntry, 0, len(tp.txs))
+	for _, e := range tp.txs {
+		entries = append(entries, e)
+	}
+	tp.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[j].less(entries[i])
+	})
+	if n > len(entries) {
+		n = len(entries)
+	}
+	txs := make([]*transaction.Transaction, 0, n)
+	for _, e := range entries[:n] {
+		txs = append(txs, e.tx)
+	}
+	return txs
+}
+
+// RemoveTransactions remove transactions from pool, i.e. which have been persisted
+func (tp *TxPool) RemoveTransactions(txs []*transaction.Transaction) {
+	tp.Lock()
+	defer tp.Unlock()
+	for _, tx := range txs {
+		delete(tp.txs, tx.Hash())
+	}
+}
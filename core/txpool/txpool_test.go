@@ -0,0 +1,127 @@
+package txpool
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/signature/signaturetest"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/errors"
+	"github.com/mileschao/echain/smartcontract/types"
+)
+
+type testChecker map[common.Uint256]bool
+
+func (tc testChecker) ContainTransaction(txHash common.Uint256) (bool, error) {
+	return tc[txHash], nil
+}
+
+func newSignedTx(t *testing.T, signer *signaturetest.Signatory, nonce uint32, gasPrice uint64) *transaction.Transaction {
+	tx, err := transaction.NewInvokeTx(types.VMCode{
+		VMType: types.NEOVM,
+		Code:   []byte{0xFF},
+	})
+	if err != nil {
+		t.Fatalf("new invoke tx: %s", err)
+	}
+	tx.Nonce = nonce
+	tx.GasPrice = gasPrice
+	tx.Payer = common.AddressFromPubKey(signer.PublicKey())
+	if err := transaction.SignTransaction(tx, signer); err != nil {
+		t.Fatalf("sign transaction: %s", err)
+	}
+	return tx
+}
+
+func TestTxPoolAddTransaction(t *testing.T) {
+	signer := signaturetest.NewSignatory(t)
+	persisted := newSignedTx(t, signer, 0, 1)
+	tp := NewTxPool(10, testChecker{persisted.Hash(): true})
+
+	tx := newSignedTx(t, signer, 1, 1)
+	if err := tp.AddTransaction(tx); err != nil {
+		t.Errorf("add transaction: %s", err)
+	}
+	if err := tp.AddTransaction(tx); errors.ErrorCode(err) != errors.ErrTxHashDuplicate {
+		t.Errorf("add duplicated transaction: %v", err)
+	}
+	if err := tp.AddTransaction(persisted); errors.ErrorCode(err) != errors.ErrDuplicatedTx {
+		t.Errorf("add persisted transaction: %v", err)
+	}
+	unsigned := newSignedTx(t, signer, 2, 1)
+	unsigned.Sigs = nil
+	if err := tp.AddTransaction(unsigned); errors.ErrorCode(err) != errors.ErrVerifySignature {
+		t.Errorf("add unsigned transaction: %v", err)
+	}
+	if tp.Size() != 1 || tp.GetTransaction(tx.Hash()) != tx {
+		t.Errorf("tx pool size: %d", tp.Size())
+	}
+	tp.RemoveTransactions([]*transaction.Transaction{tx})
+	if tp.Size() != 0 || tp.GetTransaction(tx.Hash()) != nil {
+		t.Errorf("remove transactions: %d", tp.Size())
+	}
+}
+
+func TestTxPoolEviction(t *testing.T) {
+	signer := signaturetest.NewSignatory(t)
+	tp := NewTxPool(3, nil)
+	txs := []*transaction.Transaction{
+		newSignedTx(t, signer, 0, 2),
+		newSignedTx(t, signer, 1, 1),
+		newSignedTx(t, signer, 2, 3),
+	}
+	for _, tx := range txs {
+		if err := tp.AddTransaction(tx); err != nil {
+			t.Errorf("add transaction: %s", err)
+		}
+	}
+	if err := tp.AddTransaction(newSignedTx(t, signer, 3, 1)); errors.ErrorCode(err) != errors.ErrTxPoolFull {
+		t.Errorf("add transaction with lowest gas price into full pool: %v", err)
+	}
+	higher := newSignedTx(t, signer, 4, 2)
+	if err := tp.AddTransaction(higher); err != nil {
+		t.Errorf("add transaction with higher gas price into full pool: %s", err)
+	}
+	if tp.GetTransaction(txs[1].Hash()) != nil {
+		t.Errorf("lowest gas price transaction not evicted")
+	}
+
+	batch := tp.GetBatch(10)
+	expect := []*transaction.Transaction{txs[2], txs[0], higher}
+	if len(batch) != len(expect) {
+		t.Fatalf("get batch: %d", len(batch))
+	}
+	for i := range expect {
+		if batch[i] != expect[i] {
+			t.Errorf("get batch order: %d, %d", i, batch[i].Nonce)
+		}
+	}
+	if batch := tp.GetBatch(1); len(batch) != 1 || batch[0] != txs[2] {
+		t.Errorf("get batch with limit: %d", len(batch))
+	}
+}
+
+func TestTxPoolConcurrent(t *testing.T) {
+	signer := signaturetest.NewSignatory(t)
+	tp := NewTxPool(100, nil)
+	var txs []*transaction.Transaction
+	for i := 0; i < 50; i++ {
+		txs = append(txs, newSignedTx(t, signer, uint32(i), uint64(i%5)))
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, tx := range txs {
+				tp.AddTransaction(tx)
+				tp.GetBatch(10)
+			}
+		}()
+	}
+	wg.Wait()
+	if tp.Size() != len(txs) {
+		t.Errorf("tx pool size: %d", tp.Size())
+	}
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/errors"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+var (
+	// ErrGenesisBlock genesis block is not at height 0 of the chain, or its
+	// Bookkeeper transactions do not match the bookkeepers of header
+	ErrGenesisBlock = errors.NewErr("invalid genesis block")
+)
+
+// ValidateGenesisBlock check the block at height 0, i.e. built by genesis.BuildGenesisBlock.
+// its Bookkeeper transactions are self issued without cert by the initial bookkeepers,
+// who must be the bookkeepers of header. the other transactions are checked as ValidateTransaction
+func ValidateGenesisBlock(blk *block.Block, cfg *Config) error {
+	if err := blk.Verify(); err != nil {
+		return err
+	}
+	header := blk.Header
+	if header.Height != 0 || headerChainID(header) != cfg.ChainID || len(header.Bookkeepers) == 0 {
+		return ErrGenesisBlock
+	}
+	next, err := block.AddressFromBookkeepers(header.Bookkeepers)
+	if err != nil {
+		return err
+	}
+	if next != header.NextBookkeeper {
+		return ErrGenesisBlock
+	}
+	var pks []keypair.PublicKey
+	for _, tx := range blk.Transactions {
+		if err := validateTransaction(tx, cfg, validateGenesisPayload); err != nil {
+			return err
+		}
+		if pl, ok := tx.Payload.(*payload.Bookkeeper); ok {
+			pks = append(pks, pl.PubKey)
+		}
+	}
+	if len(pks) != len(header.Bookkeepers) {
+		return ErrGenesisBlock
+	}
+	for i, pk := range pks {
+		if !keypair.ComparePublicKey(pk, header.Bookkeepers[i]) {
+			return ErrGenesisBlock
+		}
+	}
+	return nil
+}
+
+// validateGenesisPayload the Bookkeeper payload must add the issuer itself at height 0
+// without cert, the other payloads are checked by validatePayload
+func validateGenesisPayload(tx *transaction.Transaction, cfg *Config) error {
+	if tx.TxType != transaction.Bookkeeper {
+		return validatePayload(tx, cfg)
+	}
+	pl, ok := tx.Payload.(*payload.Bookkeeper)
+	if !ok {
+		return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+	}
+	if pl.PubKey == nil || pl.Action != payload.BookkeeperActionADD || pl.Height != 0 ||
+		len(pl.Cert) != 0 || !keypair.ComparePublicKey(pl.PubKey, pl.Issuer) {
+		return errors.NewDetailErr(ErrPayload, errors.ErrTransactionPayload, "")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"time"
+
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/errors"
+)
+
+const (
+	// MaxBlockTimeDrift max seconds that block timestamp can be ahead of local time
+	MaxBlockTimeDrift = 10 * 60
+)
+
+var (
+	// ErrPrevBlockHash header is not linked to the previous header
+	ErrPrevBlockHash = errors.NewErr("previous block hash mismatch")
+	// ErrHeaderChainID header is not of the same chain as the previous header
+	ErrHeaderChainID = errors.NewErr("block of other chain")
+	// ErrBlockHeight header height is not the next of previous header
+	ErrBlockHeight = errors.NewErr("block height is not the next of previous block")
+	// ErrBlockTimestamp header timestamp is not after the previous or too far in the future
+	ErrBlockTimestamp = errors.NewErr("invalid block timestamp")
+	// ErrBookkeepers bookkeepers do not match NextBookkeeper of previous header
+	ErrBookkeepers = errors.NewErr("bookkeepers do not match previous next bookkeeper")
+)
+
+// ValidateHeader check the header against the previous header at the local time now.
+// the header must be signed by at least block.BookkeeperThreshold of its bookkeepers,
+// who are announced by NextBookkeeper of the previous header
+func ValidateHeader(prev, cur *block.Header, now time.Time) error {
+	if cur.PrevBlockHash != prev.Hash() {
+		return ErrPrevBlockHash
+	}
+	if headerChainID(cur) != headerChainID(prev) {
+		return ErrHeaderChainID
+	}
+	if cur.Height != prev.Height+1 {
+		return ErrBlockHeight
+	}
+	if cur.Timestamp <= prev.Timestamp ||
+		int64(cur.Timestamp) > now.Unix()+MaxBlockTimeDrift {
+		return ErrBlockTimestamp
+	}
+	if len(cur.Bookkeepers) == 0 {
+		return ErrBookkeepers
+	}
+	addr, err := block.AddressFromBookkeepers(cur.Bookkeepers)
+	if err != nil {
+		return err
+	}
+	if addr != prev.NextBookkeeper {
+		return ErrBookkeepers
+	}
+	hash := cur.Hash()
+	m := block.BookkeeperThreshold(len(cur.Bookkeepers))
+	return signature.VerifyMultiSignature(hash[:], cur.Bookkeepers, m, cur.SigData)
+}
+
+// headerChainID the headers without ChainID are regarded as chain 0
+func headerChainID(header *block.Header) uint32 {
+	if header.HasChainID() {
+		return header.ChainID
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mileschao/echain/core/block"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/core/signature/signaturetest"
+)
+
+func signHeader(t *testing.T, header *block.Header, signers []*signaturetest.Signatory) {
+	hash := header.Hash()
+	header.SigData = nil
+	for _, s := range signers {
+		sig, err := signature.Sign(s, hash[:])
+		if err != nil {
+			t.Fatalf("sign header: %s", err)
+		}
+		header.SigData = append(header.SigData, sig)
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	signers, pks := signaturetest.NewSignatories(t, 4)
+	next, err := block.AddressFromBookkeepers(pks)
+	if err != nil {
+		t.Fatalf("address from bookkeepers: %s", err)
+	}
+	local := time.Unix(1530000000, 0)
+	now := uint32(local.Unix())
+	prev := &block.Header{
+		Height:         10,
+		Timestamp:      now - 10,
+		NextBookkeeper: next,
+	}
+	newHeader := func() *block.Header {
+		return &block.Header{
+			PrevBlockHash:  prev.Hash(),
+			Height:         11,
+			Timestamp:      now,
+			NextBookkeeper: next,
+			Bookkeepers:    pks,
+		}
+	}
+
+	cur := newHeader()
+	signHeader(t, cur, signers[1:])
+	if err := ValidateHeader(prev, cur, local); err != nil {
+		t.Errorf("validate header: %s", err)
+	}
+	signHeader(t, cur, signers[2:])
+	if err := ValidateHeader(prev, cur, local); err != signature.ErrNotEnoughtSignature {
+		t.Errorf("validate header with 2 of 4 signatures: %v", err)
+	}
+
+	cur = newHeader()
+	cur.PrevBlockHash[0] ^= 0xFF
+	if err := ValidateHeader(prev, cur, local); err != ErrPrevBlockHash {
+		t.Errorf("validate header linkage: %v", err)
+	}
+	cur = newHeader()
+	cur.Version = block.HeaderVersionChainID
+	cur.ChainID = 1
+	if err := ValidateHeader(prev, cur, local); err != ErrHeaderChainID {
+		t.Errorf("validate header chain id: %v", err)
+	}
+	cur = newHeader()
+	cur.Height = 12
+	if err := ValidateHeader(prev, cur, local); err != ErrBlockHeight {
+		t.Errorf("validate header height: %v", err)
+	}
+	cur = newHeader()
+	cur.Timestamp = prev.Timestamp
+	if err := ValidateHeader(prev, cur, local); err != ErrBlockTimestamp {
+		t.Errorf("validate header timestamp: %v", err)
+	}
+	cur = newHeader()
+	cur.Timestamp = now + MaxBlockTimeDrift
+	signHeader(t, cur, signers)
+	if err := ValidateHeader(prev, cur, local); err != nil {
+		t.Errorf("validate header at max drift: %v", err)
+	}
+	cur = newHeader()
+	cur.Timestamp = now + MaxBlockTimeDrift + 1
+	signHeader(t, cur, signers)
+	if err := ValidateHeader(prev, cur, local); err != ErrBlockTimestamp {
+		t.Errorf("validate header future timestamp: %v", err)
+	}
+	cur = newHeader()
+	cur.Bookkeepers = pks[1:]
+	signHeader(t, cur, signers[1:])
+	if err := ValidateHeader(prev, cur, local); err != ErrBookkeepers {
+		t.Errorf("validate header bookkeepers: %v", err)
+	}
+}
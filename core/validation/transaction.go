@@ -0,0 +1,249 @@
+package validation
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/errors"
+)
+
+const (
+	// MaxAttributes max number of attributes in transaction
+	MaxAttributes = transaction.MaxTxAttributes
+	// MaxNonceSize max data size of Nonce attribute
+	MaxNonceSize = 32
+	// MaxDescriptionURLSize max data size of DescriptionURL attribute
+	MaxDescriptionURLSize = 255
+	// MaxDescriptionSize max data size of Description attribute
+	MaxDescriptionSize = 65535
+	// MaxDeployFieldSize max size of name, version, author and email of deploy code
+	MaxDeployFieldSize = payload.MaxDeployFieldSize
+	// MaxDeployDescriptionSize max size of description of deploy code
+	MaxDeployDescriptionSize = payload.MaxDeployDescriptionSize
+)
+
+var (
+	// ErrVersion transaction version is higher than supported
+	ErrVersion = errors.NewErr("unsupported transaction version")
+	// ErrChainID transaction is not bound to this chain
+	ErrChainID = errors.NewErr("transaction of other chain")
+	// ErrTxType unknown transaction type
+	ErrTxType = errors.NewErr("unknown transaction type")
+	// ErrGasPrice gas price is lower than minimum
+	ErrGasPrice = errors.NewErr("gas price too low")
+	// ErrGasLimit gas limit out of range
+	ErrGasLimit = errors.NewErr("gas limit out of range")
+	// ErrTxSize serialized transaction is too large
+	ErrTxSize = errors.NewErr("transaction too large")
+	// ErrPayloadType payload do not match transaction type
+	ErrPayloadType = errors.NewErr("payload do not match transaction type")
+	// ErrPayload invalid payload content
+	ErrPayload = errors.NewErr("invalid payload")
+	// ErrBookkeeperCert cert of bookkeeper transaction is not signed by issuer for this chain
+	ErrBookkeeperCert = errors.NewErr("invalid bookkeeper cert")
+	// ErrDuplicateInput claim references the same input more than once
+	ErrDuplicateInput = errors.NewErr("duplicate claim input")
+	// ErrAttributes too many attributes
+	ErrAttributes = errors.NewErr("too many attributes")
+	// ErrAttributeUsage unsupported attribute usage
+	ErrAttributeUsage = errors.NewErr("unsupported attribute usage")
+	// ErrAttributeSize attribute data size out of range
+	ErrAttributeSize = errors.NewErr("attribute data size out of range")
+)
+
+// PayloadValidator check the payload of transaction type registered by
+// transaction.RegisterPayload, the transaction has passed the common checks
+type PayloadValidator func(tx *transaction.Transaction) error
+
+var (
+	validatorLock     sync.RWMutex
+	payloadValidators = make(map[transaction.TxType]PayloadValidator)
+)
+
+// RegisterPayloadValidator register payload validator of the transaction type,
+// which is registered by transaction.RegisterPayload. the builtin types are
+// always validated by this package
+func RegisterPayloadValidator(txType transaction.TxType, validator PayloadValidator) {
+	validatorLock.Lock()
+	defer validatorLock.Unlock()
+	payloadValidators[txType] = validator
+}
+
+// Config bounds of transaction validation
+type Config struct {
+	ChainID     uint32 // chain which transactions must be bound to, 0 accepts those without ChainID
+	MinGasPrice uint64 // min gas price of all transactions
+	MinGasLimit uint64 // min gas limit of Deploy and Invoke transactions
+	MaxGasLimit uint64 // max gas limit of all transactions
+	MaxTxSize   int    // max serialized size of transaction
+}
+
+// DefaultConfig default bounds of transaction validation
+var DefaultConfig = Config{
+	MinGasPrice: 0,
+	MinGasLimit: 20000,
+	MaxGasLimit: 100000000,
+	MaxTxSize:   1024 * 1024,
+}
+
+// ValidateTransaction check the transaction without ledger state
+// the error returned carries errors.ErrCode, see errors.ErrorCode
+func ValidateTransaction(tx *transaction.Transaction, cfg *Config) error {
+	return validateTransaction(tx, cfg, validatePayload)
+}
+
+// validateTransaction check the transaction with its payload checked by checkPayload
+func validateTransaction(tx *transaction.Transaction, cfg *Config,
+	checkPayload func(*transaction.Transaction, *Config) error) error {
+	if tx.Version > transaction.TxVersion {
+		return errors.NewDetailErr(ErrVersion, errors.ErrTransactionVersion, "")
+	}
+	if err := validateChainID(tx, cfg); err != nil {
+		return err
+	}
+	if err := checkPayload(tx, cfg); err != nil {
+		return err
+	}
+	if err := validateGas(tx, cfg); err != nil {
+		return err
+	}
+	if err := validateAttributes(tx.Attributes); err != nil {
+		return err
+	}
+	if len(tx.Bytes()) > cfg.MaxTxSize {
+		return errors.NewDetailErr(ErrTxSize, errors.ErrTransactionSize, "")
+	}
+	return nil
+}
+
+// validateChainID the transactions without ChainID are regarded as chain 0
+func validateChainID(tx *transaction.Transaction, cfg *Config) error {
+	var chainID uint32
+	if tx.HasChainID() {
+		chainID = tx.ChainID
+	}
+	if chainID != cfg.ChainID {
+		return errors.NewDetailErr(ErrChainID, errors.ErrTransactionChainID, "")
+	}
+	return nil
+}
+
+func validateGas(tx *transaction.Transaction, cfg *Config) error {
+	if tx.GasPrice < cfg.MinGasPrice {
+		return errors.NewDetailErr(ErrGasPrice, errors.ErrTransactionGas, "")
+	}
+	if tx.GasLimit > cfg.MaxGasLimit {
+		return errors.NewDetailErr(ErrGasLimit, errors.ErrTransactionGas, "")
+	}
+	if (tx.TxType == transaction.Deploy || tx.TxType == transaction.Invoke) && tx.GasLimit < cfg.MinGasLimit {
+		return errors.NewDetailErr(ErrGasLimit, errors.ErrTransactionGas, "")
+	}
+	return nil
+}
+
+func validatePayload(tx *transaction.Transaction, cfg *Config) error {
+	var valid bool
+	switch tx.TxType {
+	case transaction.Bookkeeper:
+		pl, ok := tx.Payload.(*payload.Bookkeeper)
+		if !ok {
+			return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+		}
+		valid = pl.PubKey != nil && pl.Issuer != nil && len(pl.Cert) > 0 &&
+			(pl.Action == payload.BookkeeperActionADD || pl.Action == payload.BookkeeperActionSUB)
+		// the cert must be issued for this chain, its height is checked when the block is applied
+		if valid && signature.Verify(pl.Issuer, pl.CertData(cfg.ChainID), pl.Cert) != nil {
+			return errors.NewDetailErr(ErrBookkeeperCert, errors.ErrTransactionPayload, "")
+		}
+	case transaction.Deploy:
+		pl, ok := tx.Payload.(*payload.DeployCode)
+		if !ok {
+			return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+		}
+		valid = pl.IsValid()
+	case transaction.Invoke:
+		pl, ok := tx.Payload.(*payload.InvokeCode)
+		if !ok {
+			return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+		}
+		valid = pl.IsValid()
+	case transaction.Vote:
+		pl, ok := tx.Payload.(*payload.Vote)
+		if !ok {
+			return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+		}
+		// the voter must be the payer, who has signed the transaction
+		valid = pl.IsValid() && pl.Account == tx.Payer
+	case transaction.Enrollment:
+		pl, ok := tx.Payload.(*payload.Enrollment)
+		if !ok {
+			return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+		}
+		// the candidate must be the payer, who has signed the transaction
+		valid = pl.IsValid() && tx.Payer == common.AddressFromPubKey(pl.PubKey)
+	case transaction.Claim:
+		pl, ok := tx.Payload.(*payload.Claim)
+		if !ok {
+			return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+		}
+		if pl.IsValid() && pl.HasDuplicateInput() {
+			return errors.NewDetailErr(ErrDuplicateInput, errors.ErrDuplicateInput, "")
+		}
+		valid = pl.IsValid()
+	default:
+		return validateRegisteredPayload(tx)
+	}
+	if !valid {
+		return errors.NewDetailErr(ErrPayload, errors.ErrTransactionPayload, "")
+	}
+	return nil
+}
+
+// validateRegisteredPayload the transaction type must be registered, and the payload
+// must be of the registered type. the registered validator is applied if exists
+func validateRegisteredPayload(tx *transaction.Transaction) error {
+	pl, err := transaction.NewPayload(tx.TxType)
+	if err != nil {
+		return errors.NewDetailErr(ErrTxType, errors.ErrTransactionType, "")
+	}
+	if reflect.TypeOf(pl) != reflect.TypeOf(tx.Payload) {
+		return errors.NewDetailErr(ErrPayloadType, errors.ErrTransactionPayload, "")
+	}
+	validatorLock.RLock()
+	validator := payloadValidators[tx.TxType]
+	validatorLock.RUnlock()
+	if validator == nil {
+		return nil
+	}
+	return validator(tx)
+}
+
+func validateAttributes(attrs []*transaction.TxAttribute) error {
+	if len(attrs) > MaxAttributes {
+		return errors.NewDetailErr(ErrAttributes, errors.ErrAttributeProgram, "")
+	}
+	for _, attr := range attrs {
+		if !attr.Usage.IsValid() {
+			return errors.NewDetailErr(ErrAttributeUsage, errors.ErrAttributeProgram, "")
+		}
+		var valid bool
+		switch attr.Usage {
+		case transaction.Nonce:
+			valid = len(attr.Data) <= MaxNonceSize
+		case transaction.Script:
+			valid = len(attr.Data) == common.ADDR_LEN
+		case transaction.DescriptionURL:
+			valid = len(attr.Data) <= MaxDescriptionURLSize
+		case transaction.Description:
+			valid = len(attr.Data) <= MaxDescriptionSize
+		}
+		if !valid {
+			return errors.NewDetailErr(ErrAttributeSize, errors.ErrAttributeProgram, "")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common"
+	"github.com/mileschao/echain/core/payload"
+	"github.com/mileschao/echain/core/signature"
+	"github.com/mileschao/echain/core/signature/signaturetest"
+	"github.com/mileschao/echain/core/transaction"
+	"github.com/mileschao/echain/errors"
+	"github.com/mileschao/echain/smartcontract/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func newInvokeTx() *transaction.Transaction {
+	return &transaction.Transaction{
+		Version:  transaction.TxVersion,
+		TxType:   transaction.Invoke,
+		GasLimit: DefaultConfig.MinGasLimit,
+		Payload:  &payload.InvokeCode{Code: types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}},
+	}
+}
+
+func TestValidateTransaction(t *testing.T) {
+	if err := ValidateTransaction(newInvokeTx(), &DefaultConfig); err != nil {
+		t.Errorf("validate transaction: %s", err)
+	}
+
+	tx := newInvokeTx()
+	tx.Version = transaction.TxVersion + 1
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrTransactionVersion {
+		t.Errorf("validate transaction version: %v", err)
+	}
+
+	tx = newInvokeTx()
+	tx.TxType = 0xFF
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrTransactionType {
+		t.Errorf("validate transaction type: %v", err)
+	}
+
+	tx = newInvokeTx()
+	tx.TxType = transaction.Deploy
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrPayloadType {
+		t.Errorf("validate payload type: %v", err)
+	}
+
+	tx = newInvokeTx()
+	tx.Payload = &payload.InvokeCode{Code: types.VMCode{VMType: 0x01, Code: []byte{0xFF}}}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrTransactionPayload {
+		t.Errorf("validate invoke code vm type: %v", err)
+	}
+
+	tx = newInvokeTx()
+	tx.Payload = &payload.InvokeCode{Code: types.VMCode{VMType: types.NEOVM}}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate empty invoke code: %v", err)
+	}
+}
+
+func TestValidateTransactionGas(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.MinGasPrice = 10
+
+	tx := newInvokeTx()
+	if err := ValidateTransaction(tx, &cfg); errors.RootErr(err) != ErrGasPrice {
+		t.Errorf("validate gas price: %v", err)
+	}
+	tx.GasPrice = 10
+	tx.GasLimit = cfg.MinGasLimit - 1
+	if err := ValidateTransaction(tx, &cfg); errors.RootErr(err) != ErrGasLimit {
+		t.Errorf("validate min gas limit: %v", err)
+	}
+	tx.GasLimit = cfg.MaxGasLimit + 1
+	if err := ValidateTransaction(tx, &cfg); errors.ErrorCode(err) != errors.ErrTransactionGas {
+		t.Errorf("validate max gas limit: %v", err)
+	}
+}
+
+func TestValidateTransactionAttributes(t *testing.T) {
+	tx := newInvokeTx()
+	attr := transaction.NewTxAttribute(transaction.Script, make([]byte, common.ADDR_LEN))
+	tx.Attributes = []*transaction.TxAttribute{&attr}
+	if err := ValidateTransaction(tx, &DefaultConfig); err != nil {
+		t.Errorf("validate attributes: %s", err)
+	}
+
+	attr = transaction.NewTxAttribute(transaction.Script, make([]byte, 1))
+	tx.Attributes = []*transaction.TxAttribute{&attr}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrAttributeSize {
+		t.Errorf("validate attribute size: %v", err)
+	}
+
+	attr = transaction.NewTxAttribute(0x01, nil)
+	tx.Attributes = []*transaction.TxAttribute{&attr}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrAttributeUsage {
+		t.Errorf("validate attribute usage: %v", err)
+	}
+
+	tx.Attributes = nil
+	for i := 0; i <= MaxAttributes; i++ {
+		attr := transaction.NewTxAttribute(transaction.Nonce, []byte{byte(i)})
+		tx.Attributes = append(tx.Attributes, &attr)
+	}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrAttributeProgram {
+		t.Errorf("validate attribute number: %v", err)
+	}
+}
+
+func TestValidateTransactionSize(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.MaxTxSize = 64
+	tx := newInvokeTx()
+	tx.Payload = &payload.InvokeCode{Code: types.VMCode{VMType: types.NEOVM, Code: make([]byte, 64)}}
+	if err := ValidateTransaction(tx, &cfg); errors.ErrorCode(err) != errors.ErrTransactionSize {
+		t.Errorf("validate transaction size: %v", err)
+	}
+}
+
+func TestValidateVoteTransaction(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key pair: %s", err)
+	}
+	vote := &payload.Vote{
+		PubKeys: []keypair.PublicKey{pk},
+		Account: common.AddressFromPubKey(pk),
+	}
+	tx := &transaction.Transaction{
+		TxType:  transaction.Vote,
+		Payload: vote,
+		Payer:   vote.Account,
+	}
+	if err := ValidateTransaction(tx, &DefaultConfig); err != nil {
+		t.Errorf("validate vote: %s", err)
+	}
+
+	tx = &transaction.Transaction{
+		TxType:  transaction.Vote,
+		Payload: vote,
+	}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate vote of other account: %v", err)
+	}
+
+	tx = &transaction.Transaction{
+		TxType:  transaction.Vote,
+		Payload: &payload.Vote{PubKeys: make([]keypair.PublicKey, payload.MaxVoteKeys+1)},
+	}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrTransactionPayload {
+		t.Errorf("validate vote keys: %v", err)
+	}
+}
+
+func TestValidateBookkeeperTransaction(t *testing.T) {
+	signers, pks := signaturetest.NewSignatories(t, 2)
+	cfg := DefaultConfig
+	cfg.ChainID = 1
+	bk := &payload.Bookkeeper{
+		PubKey: pks[1],
+		Action: payload.BookkeeperActionADD,
+		Height: 1,
+		Issuer: pks[0],
+	}
+	cert, err := signature.Sign(signers[0], bk.CertData(cfg.ChainID))
+	if err != nil {
+		t.Fatalf("sign cert: %s", err)
+	}
+	bk.Cert = cert
+	tx := &transaction.Transaction{TxType: transaction.Bookkeeper, Payload: bk}
+	tx.SetChainID(cfg.ChainID)
+	if err := ValidateTransaction(tx, &cfg); err != nil {
+		t.Errorf("validate bookkeeper: %s", err)
+	}
+
+	// cert of chain 1 replayed on chain 2
+	cfg.ChainID = 2
+	tx.SetChainID(cfg.ChainID)
+	if err := ValidateTransaction(tx, &cfg); errors.RootErr(err) != ErrBookkeeperCert {
+		t.Errorf("validate bookkeeper cert of other chain: %v", err)
+	}
+}
+
+func TestValidateEnrollmentAndClaim(t *testing.T) {
+	_, pk, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		t.Fatalf("generate key pair: %s", err)
+	}
+	enroll, err := transaction.NewEnrollmentTx(pk, 1000)
+	if err != nil {
+		t.Fatalf("new enrollment tx: %s", err)
+	}
+	if err := ValidateTransaction(enroll, &DefaultConfig); err != nil {
+		t.Errorf("validate enrollment: %s", err)
+	}
+	enroll.Payload.(*payload.Enrollment).Deposit = 0
+	if err := ValidateTransaction(enroll, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate enrollment without deposit: %v", err)
+	}
+	enroll.Payload.(*payload.Enrollment).Deposit = 1000
+	enroll.Payer = common.Address{0xFF}
+	if err := ValidateTransaction(enroll, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate enrollment of other payer: %v", err)
+	}
+
+	input := &payload.ClaimInput{PrevHash: common.Uint256{0xFF}}
+	claim, err := transaction.NewClaimTx([]*payload.ClaimInput{input})
+	if err != nil {
+		t.Fatalf("new claim tx: %s", err)
+	}
+	if err := ValidateTransaction(claim, &DefaultConfig); err != nil {
+		t.Errorf("validate claim: %s", err)
+	}
+	claim.Payload = &payload.Claim{}
+	if err := ValidateTransaction(claim, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate claim without input: %v", err)
+	}
+	claim.Payload = &payload.Claim{Claims: []*payload.ClaimInput{input, input}}
+	if err := ValidateTransaction(claim, &DefaultConfig); errors.ErrorCode(err) != errors.ErrDuplicateInput {
+		t.Errorf("validate claim with duplicate input: %v", err)
+	}
+	claim.Payload = &payload.Claim{Claims: []*payload.ClaimInput{input, nil}}
+	if err := ValidateTransaction(claim, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate claim with nil input: %v", err)
+	}
+}
+
+func TestValidateRegisteredTransaction(t *testing.T) {
+	const custom transaction.TxType = 0xE0
+	tx := newInvokeTx()
+	tx.TxType = custom
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrTransactionType {
+		t.Errorf("validate unregistered transaction type: %v", err)
+	}
+
+	transaction.RegisterPayload(custom, func() payload.Payload { return new(payload.InvokeCode) })
+	if err := ValidateTransaction(tx, &DefaultConfig); err != nil {
+		t.Errorf("validate registered transaction type: %s", err)
+	}
+	tx.Payload = &payload.Claim{}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrPayloadType {
+		t.Errorf("validate registered transaction type with other payload: %v", err)
+	}
+
+	RegisterPayloadValidator(custom, func(tx *transaction.Transaction) error {
+		if !tx.Payload.(*payload.InvokeCode).IsValid() {
+			return errors.NewDetailErr(ErrPayload, errors.ErrTransactionPayload, "")
+		}
+		return nil
+	})
+	tx.Payload = &payload.InvokeCode{Code: types.VMCode{VMType: types.NEOVM, Code: []byte{0xFF}}}
+	if err := ValidateTransaction(tx, &DefaultConfig); err != nil {
+		t.Errorf("validate registered transaction: %s", err)
+	}
+	tx.Payload = &payload.InvokeCode{}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.RootErr(err) != ErrPayload {
+		t.Errorf("validate registered transaction with validator: %v", err)
+	}
+}
+
+func TestValidateTransactionChainID(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.ChainID = 1
+
+	tx := newInvokeTx()
+	if err := ValidateTransaction(tx, &cfg); errors.ErrorCode(err) != errors.ErrTransactionChainID {
+		t.Errorf("validate transaction without chain id: %v", err)
+	}
+	tx.SetChainID(2)
+	if err := ValidateTransaction(tx, &cfg); errors.ErrorCode(err) != errors.ErrTransactionChainID {
+		t.Errorf("validate transaction of other chain: %v", err)
+	}
+	tx.SetChainID(1)
+	if err := ValidateTransaction(tx, &cfg); err != nil {
+		t.Errorf("validate transaction with chain id: %s", err)
+	}
+	if err := ValidateTransaction(tx, &DefaultConfig); errors.ErrorCode(err) != errors.ErrTransactionChainID {
+		t.Errorf("validate transaction with chain id on chain 0: %v", err)
+	}
+}
@@ -32,6 +32,12 @@ const (
 	ErrNetPackFail          ErrCode = 45017
 	ErrNetUnPackFail        ErrCode = 45018
 	ErrNetVerifyFail        ErrCode = 45019
+	ErrVerifySignature      ErrCode = 45020
+	ErrTransactionVersion   ErrCode = 45021
+	ErrTransactionType      ErrCode = 45022
+	ErrTransactionGas       ErrCode = 45023
+	ErrTransactionSize      ErrCode = 45024
+	ErrTransactionChainID   ErrCode = 45025
 )
 
 func (err ErrCode) Error() string {
@@ -76,6 +82,18 @@ func (err ErrCode) Error() string {
 		return "net msg unpack fail"
 	case ErrNetVerifyFail:
 		return "net msg verify fail"
+	case ErrVerifySignature:
+		return "transaction signature verification failed"
+	case ErrTransactionVersion:
+		return "invalid transaction version"
+	case ErrTransactionType:
+		return "invalid transaction type"
+	case ErrTransactionGas:
+		return "transaction gas out of range"
+	case ErrTransactionSize:
+		return "transaction size out of range"
+	case ErrTransactionChainID:
+		return "transaction of other chain"
 	}
 
 	return fmt.Sprintf("Unknown error? Error code = %d", err)
@@ -24,6 +24,7 @@ type HashStorage interface {
 	Flush() error
 	Close()
 	GetHash(pos uint32) (common.Uint256, error)
+	Truncate(num uint64) error
 }
 
 // fileHashStorage an implementation of HashStorage interface
@@ -51,6 +52,10 @@ func NewFileHashStorage(name string, leafSize uint64) (HashStorage, error) {
 	num := totalStoredHashNum(leafSize)
 	size := int64(num) * int64(common.UINT256_SIZE)
 
+	// drop the hashes appended after the leafSize was stored
+	if err = store.file.Truncate(size); err != nil {
+		return nil, err
+	}
 	_, err = store.file.Seek(size, io.SeekStart)
 	if err != nil {
 		return nil, err
@@ -125,6 +130,20 @@ func (fhs *fileHashStorage) GetHash(pos uint32) (common.Uint256, error) {
 	return hash, nil
 }
 
+// Truncate implement HashStorage interface
+// drop the hashes after the first num hashes
+func (fhs *fileHashStorage) Truncate(num uint64) error {
+	if fhs.file == nil {
+		return nil
+	}
+	size := int64(num) * int64(common.UINT256_SIZE)
+	if err := fhs.file.Truncate(size); err != nil {
+		return err
+	}
+	_, err := fhs.file.Seek(size, io.SeekStart)
+	return err
+}
+
 type memoryHashStorage struct {
 	hashes []common.Uint256
 }
@@ -147,3 +166,10 @@ func (mhs *memoryHashStorage) GetHash(pos uint32) (common.Uint256, error) {
 	}
 	return mhs.hashes[pos], nil
 }
+
+func (mhs *memoryHashStorage) Truncate(num uint64) error {
+	if num < uint64(len(mhs.hashes)) {
+		mhs.hashes = mhs.hashes[:num]
+	}
+	return nil
+}
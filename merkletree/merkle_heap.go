@@ -67,7 +67,9 @@ type MerkleHeap struct {
 }
 
 // NewMerkleStorage create an new merkle storage with MerkleHeap data struct
-// BUG(r): if NewMerkleStorage with leafSize != 0, the AddLeaf will lose the data
+// leafSize and hashes are the state of merkle heap that has been stored before,
+// i.e. LeafSize() and UpperNodes(), and the store must contain the hashed nodes of it.
+// it panics if the number of hashes do not match leafSize
 func NewMerkleStorage(leafSize uint64, hashes []common.Uint256, store HashStorage) *MerkleHeap {
 
 	mh := &MerkleHeap{
@@ -77,10 +79,43 @@ func NewMerkleStorage(leafSize uint64, hashes []common.Uint256, store HashStorag
 		root:        EmptyHash,
 	}
 
-	mh.update(leafSize, hashes)
+	if err := mh.update(leafSize, hashes); err != nil {
+		panic(err)
+	}
 	return mh
 }
 
+// LoadMerkleStorage create merkle storage with state deserialized from reader
+// the reader content is the output of MerkleHeap.Serialize
+func LoadMerkleStorage(r io.Reader, store HashStorage) (*MerkleHeap, error) {
+	mh := &MerkleHeap{
+		hashStorage: store,
+		root:        EmptyHash,
+	}
+	if err := mh.Deserialize(r); err != nil {
+		return nil, err
+	}
+	return mh, nil
+}
+
+// Copy get an copy of the merkle heap sharing the hash storage,
+// i.e. stage new leaves on the copy, and drop them by Revert of the original
+func (mh *MerkleHeap) Copy() *MerkleHeap {
+	c := *mh
+	c.upperNodes = make([]common.Uint256, len(mh.upperNodes))
+	copy(c.upperNodes, mh.upperNodes)
+	return &c
+}
+
+// Revert drop the hashes appended to hash storage after the state of merkle heap,
+// i.e. the hashes of leaves added to its copy
+func (mh *MerkleHeap) Revert() error {
+	if mh.hashStorage == nil {
+		return nil
+	}
+	return mh.hashStorage.Truncate(totalStoredHashNum(mh.leafSize))
+}
+
 // UpperNodes get the upper node list of merkle heap that to be hashed
 func (mh *MerkleHeap) UpperNodes() []common.Uint256 {
 	return mh.upperNodes
@@ -93,12 +128,17 @@ func (mh *MerkleHeap) LeafSize() uint64 {
 
 // Root get the root hash of the merkle tree
 func (mh *MerkleHeap) Root() common.Uint256 {
+	if mh.root == EmptyHash {
+		mh.root = reduceHash(mh.upperNodes)
+	}
 	return mh.root
 }
 
 // RootWithNewLeaf calculate hash of merkle tree's upper node list and leaf input
 func (mh *MerkleHeap) RootWithNewLeaf(leaf common.Uint256) common.Uint256 {
-	return reduceHash(append(mh.upperNodes, leaf))
+	nodes := make([]common.Uint256, 0, len(mh.upperNodes)+1)
+	nodes = append(nodes, mh.upperNodes...)
+	return reduceHash(append(nodes, leaf))
 }
 
 // AddLeaf add new leaf node hash to Merkle Tree
@@ -136,8 +176,6 @@ func (mh *MerkleHeap) AddLeaf(leaf common.Uint256) []common.Uint256 {
 	for i, v := range mh.upperNodes {
 		auditPath[size-i-1] = v
 	}
-	// BUG(r): if the pre leafSize if even number and the upperNodes is not empty
-	// the data in upperNode will lose, as to the BUG in `NewMerkleStorage`
 	nodeToStored = append(nodeToStored, leaf)
 	//mh.height = 1
 	for s := mh.leafSize; s%2 == 1; s = s >> 1 { // odd number
@@ -159,7 +197,7 @@ func (mh *MerkleHeap) AddLeaf(leaf common.Uint256) []common.Uint256 {
 	return auditPath
 }
 
-// Proofs get proofs
+// InclusionProof get the audit path of leaf m in merkle tree with n leaves
 // m is zero base leaf index, n is leafsize(thus, 1 based)
 // what is proof?
 // i.e. the merkle tree below proof is the node with `*`
@@ -182,7 +220,7 @@ func (mh *MerkleHeap) AddLeaf(leaf common.Uint256) []common.Uint256 {
 //		in the example above, they are: 6, H(7, 8), H(H12, H34)
 // 2. the right side trees of forest that the tree m belong to, do hash of all the root node of these trees
 //		in the example above is H(H910, 11)
-func (mh *MerkleHeap) Proofs(m, n uint64) ([]common.Uint256, error) {
+func (mh *MerkleHeap) InclusionProof(m, n uint64) ([]common.Uint256, error) {
 	//TODO: ugly code, make it better to read for human being
 	if m >= n {
 		return nil, errors.New("wrong parameters")
@@ -224,6 +262,93 @@ func (mh *MerkleHeap) Proofs(m, n uint64) ([]common.Uint256, error) {
 	return reverse, nil
 }
 
+// ConsistencyProof get the proof that merkle tree with n leaves is an append-only extension
+// of the merkle tree with m leaves, as defined in RFC 6962 section 2.1.2
+// i.e. the merkle tree below proof of m = 3 and n = 7 is the node with `*`
+//
+//                H
+//          /            \
+//        H               H*
+//      /    \          /   \
+//    H*      H        H     7
+//  /   \    /  \     /  \
+// 1    2   3*  4*   5   6
+//             ^                ^
+//             m = 3            n = 7
+//
+// proofs = [3, 4, H(1, 2), H(H(5, 6), 7)]
+// the principle is:
+// 1. split the tree of n leaves into the left full tree of k leaves and the right side tree,
+//		k is the largest power of 2 less than n
+// 2. if m <= k, the right side tree is appended by the new tree, then proof m in the left tree
+// 3. otherwise the left tree is shared by both trees, then proof m-k in the right side tree
+// 4. when m is the whole subtree, its root is part of proof except it is the old tree itself
+func (mh *MerkleHeap) ConsistencyProof(m, n uint64) ([]common.Uint256, error) {
+	if m == 0 || m > n {
+		return nil, errors.New("wrong parameters")
+	} else if mh.leafSize < n {
+		return nil, errors.New("not available yet")
+	} else if mh.hashStorage == nil {
+		return nil, errors.New("hash store not available")
+	}
+	return mh.subProof(0, m, n, true)
+}
+
+// subProof get consistency proof of m leaves in the tree of leaves [offset, offset + n)
+// complete is true when the m leaves are the whole old tree
+func (mh *MerkleHeap) subProof(offset, m, n uint64, complete bool) ([]common.Uint256, error) {
+	if m == n {
+		if complete {
+			return nil, nil
+		}
+		root, err := mh.rangeRoot(offset, offset+n)
+		if err != nil {
+			return nil, err
+		}
+		return []common.Uint256{root}, nil
+	}
+	k := uint64(1 << (highBit(n-1) - 1))
+	var proofs []common.Uint256
+	var root common.Uint256
+	var err error
+	if m <= k {
+		if proofs, err = mh.subProof(offset, m, k, complete); err != nil {
+			return nil, err
+		}
+		root, err = mh.rangeRoot(offset+k, offset+n)
+	} else {
+		if proofs, err = mh.subProof(offset+k, m-k, n-k, false); err != nil {
+			return nil, err
+		}
+		root, err = mh.rangeRoot(offset, offset+k)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(proofs, root), nil
+}
+
+// rangeRoot get the root of merkle tree with leaves [start, end)
+// the leaves are divided into full subtrees from left to right, and their roots are reduced,
+// thus start must be multiple of the largest full subtree
+func (mh *MerkleHeap) rangeRoot(start, end uint64) (common.Uint256, error) {
+	var roots []common.Uint256
+	for start < end {
+		size := uint64(1 << (highBit(end-start) - 1))
+		for start%size != 0 {
+			size >>= 1
+		}
+		height := highBit(size) - 1
+		root, err := mh.hashStorage.GetHash(uint32(nodeIndex(start, height)))
+		if err != nil {
+			return EmptyHash, err
+		}
+		roots = append(roots, root)
+		start += size
+	}
+	return reduceHash(roots), nil
+}
+
 // Serialize implement the common.Serialzable interface
 func (mh *MerkleHeap) Serialize(w io.Writer) error {
 	if err := binary.Write(w, binary.LittleEndian, mh.leafSize); err != nil {
@@ -244,23 +369,25 @@ func (mh *MerkleHeap) Deserialize(r io.Reader) error {
 		return err
 	}
 	num := countBit(leafSize)
-	var upperNodes []common.Uint256
+	upperNodes := make([]common.Uint256, num)
 	for i := uint32(0); i < num; i++ {
 		if err := upperNodes[i].Deserialize(r); err != nil {
 			return err
 		}
 	}
-	return nil
+	return mh.update(leafSize, upperNodes)
 }
 
 // update merkle heap with leaf size and uppernodes list
 func (mh *MerkleHeap) update(leafSize uint64, toBeHashed []common.Uint256) error {
 	bitCount := countBit(leafSize)
 	if len(toBeHashed) != int(bitCount) {
-		panic("number of hashes != num bit in tree_size")
+		return ErrBadLeafSize
 	}
 	mh.leafSize = leafSize
-	mh.upperNodes = toBeHashed
+	// copy to avoid AddLeaf modifying the array of caller
+	mh.upperNodes = make([]common.Uint256, len(toBeHashed))
+	copy(mh.upperNodes, toBeHashed)
 	mh.height = highBit(leafSize)
 	mh.root = EmptyHash
 	return nil
@@ -1,9 +1,14 @@
 package merkletree
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -119,3 +124,76 @@ func TestProof(t *testing.T) {
 		fmt.Printf("proof: i: %d, %X\n", i, p)
 	}
 }
+
+func TestMerkleHeapReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "merkleheap")
+	if err != nil {
+		t.Fatalf("temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "block.hs")
+
+	var leaves []common.Uint256
+	for i := 0; i < 37; i++ {
+		leaves = append(leaves, common.Uint256(sha256.Sum256([]byte{byte(i)})))
+	}
+	expect := NewMerkleStorage(0, nil, &memoryHashStorage{})
+	for _, l := range leaves {
+		expect.AddLeaf(l)
+	}
+
+	fs, err := NewFileHashStorage(name, 0)
+	if err != nil {
+		t.Fatalf("new file hash storage: %s", err)
+	}
+	mh := NewMerkleStorage(0, nil, fs)
+	for _, l := range leaves[:13] {
+		mh.AddLeaf(l)
+	}
+	state := new(bytes.Buffer)
+	if err := mh.Serialize(state); err != nil {
+		t.Errorf("merkle heap serialize: %s", err)
+	}
+	// appended but not saved in state, should be dropped on reload
+	mh.AddLeaf(leaves[13])
+	fs.Close()
+
+	fs, err = NewFileHashStorage(name, 13)
+	if err != nil {
+		t.Fatalf("reopen file hash storage: %s", err)
+	}
+	defer fs.Close()
+	mh, err = LoadMerkleStorage(state, fs)
+	if err != nil {
+		t.Fatalf("load merkle storage: %s", err)
+	}
+	if mh.LeafSize() != 13 {
+		t.Errorf("merkle heap leaf size: %d", mh.LeafSize())
+	}
+	for _, l := range leaves[13:] {
+		mh.AddLeaf(l)
+	}
+
+	if mh.Root() != expect.Root() {
+		t.Errorf("merkle heap root:\n%X\n%X", mh.Root(), expect.Root())
+	}
+	for n := uint64(1); n <= uint64(len(leaves)); n++ {
+		for m := uint64(0); m < n; m++ {
+			p1, err1 := mh.InclusionProof(m, n)
+			p2, err2 := expect.InclusionProof(m, n)
+			if err1 != nil || err2 != nil || !reflect.DeepEqual(p1, p2) {
+				t.Errorf("merkle heap proof: %d, %d, %v, %v", m, n, err1, err2)
+			}
+		}
+	}
+}
+
+func TestMerkleHeapDeserialize(t *testing.T) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint64(3))
+	common.UINT256_EMPTY.Serialize(buf)
+	var mh MerkleHeap
+	if err := mh.Deserialize(buf); err == nil {
+		t.Errorf("merkle heap deserialize with less upper nodes")
+	}
+}
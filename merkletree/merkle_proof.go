@@ -0,0 +1,122 @@
+package merkletree
+
+import (
+	"errors"
+
+	"github.com/mileschao/echain/common"
+)
+
+var (
+	// ErrProofIndex leaf index or tree size is out of range
+	ErrProofIndex = errors.New("proof index out of range")
+	// ErrProofLength the number of proof hashes do not match the tree
+	ErrProofLength = errors.New("wrong proof length")
+	// ErrProofRoot the root calculated by proof do not match the expected root
+	ErrProofRoot = errors.New("proof root mismatch")
+)
+
+// VerifyLeafHashInclusion check whether leaf hash is the `index`th (zero base) leaf
+// of the merkle tree with `treeSize` leaves and root hash `root`.
+// proof is the audit path returned by MerkleHeap.InclusionProof(index, treeSize),
+// i.e. ordered from the sibling of leaf up to the subtree under root
+//
+// the principle is to walk up from the leaf, with fn the index of current node in its level,
+// and sn the index of the last node in the level:
+// 1. fn is odd or fn is the last node, the proof hash is the left sibling
+// 2. otherwise the proof hash is the right sibling
+// when the current node is the last one and has no sibling in its level,
+// it is lifted to its parent level without hashing,
+// see `reduceHash` about how the right side trees are hashed
+func VerifyLeafHashInclusion(leafHash common.Uint256, index, treeSize uint64,
+	proof []common.Uint256, root common.Uint256) error {
+	if index >= treeSize {
+		return ErrProofIndex
+	}
+	fn, sn := index, treeSize-1
+	hash := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrProofLength
+		}
+		if isOddNumber(fn) || fn == sn {
+			hash = nodeHash(p, hash)
+			for isEvenNumber(fn) && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = nodeHash(hash, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return ErrProofLength
+	}
+	if hash != root {
+		return ErrProofRoot
+	}
+	return nil
+}
+
+// VerifyConsistency check whether merkle tree with `newSize` leaves and root hash `newRoot`
+// is an append-only extension of merkle tree with `oldSize` leaves and root hash `oldRoot`.
+// proof is returned by MerkleHeap.ConsistencyProof(oldSize, newSize)
+//
+// the principle is to rebuild both old root and new root from the proof:
+// 1. when oldSize is power of 2, the old tree itself is the first node in the path
+// 2. walk up from the last node of old tree, with fn the index of current node in its level,
+//		and sn the index of the last node in the level of new tree
+// 3. left siblings are hashed into both old root and new root,
+//		right siblings are only hashed into new root
+func VerifyConsistency(oldSize, newSize uint64, oldRoot, newRoot common.Uint256,
+	proof []common.Uint256) error {
+	if oldSize == 0 || oldSize > newSize {
+		return ErrProofIndex
+	}
+	if oldSize == newSize {
+		if len(proof) != 0 {
+			return ErrProofLength
+		}
+		if oldRoot != newRoot {
+			return ErrProofRoot
+		}
+		return nil
+	}
+	if isPower2(oldSize) {
+		proof = append([]common.Uint256{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return ErrProofLength
+	}
+	fn, sn := oldSize-1, newSize-1
+	for isOddNumber(fn) {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, p := range proof[1:] {
+		if sn == 0 {
+			return ErrProofLength
+		}
+		if isOddNumber(fn) || fn == sn {
+			fr = nodeHash(p, fr)
+			sr = nodeHash(p, sr)
+			for isEvenNumber(fn) && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return ErrProofLength
+	}
+	if fr != oldRoot || sr != newRoot {
+		return ErrProofRoot
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package merkletree
+
+import (
+	"crypto/sha256"
+	"reflect"
+	"testing"
+
+	"github.com/mileschao/echain/common"
+)
+
+const proofTestLeafSize = 64
+
+// treeRoot calculate merkle tree root by definition in RFC 6962
+func treeRoot(leaves []common.Uint256) common.Uint256 {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return nodeHash(treeRoot(leaves[:k]), treeRoot(leaves[k:]))
+}
+
+func newProofTestHeap(size int) (*MerkleHeap, []common.Uint256) {
+	mh := NewMerkleStorage(0, nil, &memoryHashStorage{})
+	var leaves []common.Uint256
+	for i := 0; i < size; i++ {
+		leaf := common.Uint256(sha256.Sum256([]byte{byte(i), byte(i >> 8)}))
+		leaves = append(leaves, leaf)
+		mh.AddLeaf(leaf)
+	}
+	return mh, leaves
+}
+
+func TestMerkleHeapRoot(t *testing.T) {
+	mh := NewMerkleStorage(0, nil, &memoryHashStorage{})
+	for i := 0; i < proofTestLeafSize; i++ {
+		leaf := common.Uint256(sha256.Sum256([]byte{byte(i)}))
+		expect := mh.RootWithNewLeaf(leaf)
+		mh.AddLeaf(leaf)
+		if mh.Root() != expect {
+			t.Errorf("merkle heap root: %d\n%X\n%X", i+1, mh.Root(), expect)
+		}
+	}
+}
+
+func TestVerifyLeafHashInclusion(t *testing.T) {
+	mh, leaves := newProofTestHeap(proofTestLeafSize)
+	if mh.Root() != treeRoot(leaves) {
+		t.Errorf("merkle heap root:\n%X\n%X", mh.Root(), treeRoot(leaves))
+	}
+	for n := uint64(1); n <= proofTestLeafSize; n++ {
+		root := treeRoot(leaves[:n])
+		for m := uint64(0); m < n; m++ {
+			proof, err := mh.InclusionProof(m, n)
+			if err != nil {
+				t.Fatalf("inclusion proof: %d, %d, %s", m, n, err)
+			}
+			if err := VerifyLeafHashInclusion(leaves[m], m, n, proof, root); err != nil {
+				t.Errorf("verify inclusion: %d, %d, %s", m, n, err)
+			}
+			if m+1 < n {
+				if err := VerifyLeafHashInclusion(leaves[m+1], m, n, proof, root); err == nil {
+					t.Errorf("verify inclusion with wrong leaf: %d, %d", m, n)
+				}
+			}
+			if len(proof) > 0 {
+				bad := append([]common.Uint256{}, proof...)
+				bad[len(bad)-1][0] ^= 0xFF
+				if err := VerifyLeafHashInclusion(leaves[m], m, n, bad, root); err == nil {
+					t.Errorf("verify inclusion with wrong proof: %d, %d", m, n)
+				}
+				if err := VerifyLeafHashInclusion(leaves[m], m, n, proof[:len(proof)-1], root); err == nil {
+					t.Errorf("verify inclusion with short proof: %d, %d", m, n)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyLeafHashInclusionIndex(t *testing.T) {
+	leaf := common.Uint256(sha256.Sum256(nil))
+	if err := VerifyLeafHashInclusion(leaf, 1, 1, nil, leaf); err != ErrProofIndex {
+		t.Errorf("verify inclusion out of range: %v", err)
+	}
+	if err := VerifyLeafHashInclusion(leaf, 0, 1, nil, leaf); err != nil {
+		t.Errorf("verify inclusion of single leaf: %s", err)
+	}
+	if err := VerifyLeafHashInclusion(leaf, 0, 1, []common.Uint256{leaf}, leaf); err != ErrProofLength {
+		t.Errorf("verify inclusion with long proof: %v", err)
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	mh, leaves := newProofTestHeap(7)
+	proof, err := mh.ConsistencyProof(3, 7)
+	if err != nil {
+		t.Fatalf("consistency proof: %s", err)
+	}
+	expect := []common.Uint256{
+		leaves[2],
+		leaves[3],
+		nodeHash(leaves[0], leaves[1]),
+		nodeHash(nodeHash(leaves[4], leaves[5]), leaves[6]),
+	}
+	if !reflect.DeepEqual(proof, expect) {
+		t.Errorf("consistency proof:\n%X\n%X", proof, expect)
+	}
+	if _, err := mh.ConsistencyProof(0, 7); err == nil {
+		t.Errorf("consistency proof of empty tree")
+	}
+	if _, err := mh.ConsistencyProof(3, 8); err == nil {
+		t.Errorf("consistency proof of unavailable tree")
+	}
+}
+
+func TestVerifyConsistency(t *testing.T) {
+	mh, leaves := newProofTestHeap(proofTestLeafSize)
+	for n := uint64(1); n <= proofTestLeafSize; n++ {
+		newRoot := treeRoot(leaves[:n])
+		for m := uint64(1); m <= n; m++ {
+			oldRoot := treeRoot(leaves[:m])
+			proof, err := mh.ConsistencyProof(m, n)
+			if err != nil {
+				t.Fatalf("consistency proof: %d, %d, %s", m, n, err)
+			}
+			if err := VerifyConsistency(m, n, oldRoot, newRoot, proof); err != nil {
+				t.Errorf("verify consistency: %d, %d, %s", m, n, err)
+			}
+			if m == n {
+				continue
+			}
+			badRoot := oldRoot
+			badRoot[0] ^= 0xFF
+			if err := VerifyConsistency(m, n, badRoot, newRoot, proof); err == nil {
+				t.Errorf("verify consistency with wrong old root: %d, %d", m, n)
+			}
+			if err := VerifyConsistency(m, n, oldRoot, badRoot, proof); err == nil {
+				t.Errorf("verify consistency with wrong new root: %d, %d", m, n)
+			}
+			for i := range proof {
+				bad := append([]common.Uint256{}, proof...)
+				bad[i][0] ^= 0xFF
+				if err := VerifyConsistency(m, n, oldRoot, newRoot, bad); err == nil {
+					t.Errorf("verify consistency with wrong proof: %d, %d, %d", m, n, i)
+				}
+			}
+			if err := VerifyConsistency(m, n, oldRoot, newRoot, proof[:len(proof)-1]); err == nil {
+				t.Errorf("verify consistency with short proof: %d, %d", m, n)
+			}
+		}
+	}
+}
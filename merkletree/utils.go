@@ -148,6 +148,21 @@ func subTreeSize(leafSize uint64) []uint64 {
 	return indexes
 }
 
+// nodeIndex get index(base on 0) of node in HashStorage
+// the node is root of the full subtree with leaves [start, start + 2^height)
+// the node is stored when its last leaf is added,
+// right after the nodes below it that stored at the same time
+// i.e
+//      H12      H34 (index 5)
+//     /  \     /  \
+//    1   2    3   4 (index 4)
+//
+// when leaf 4 added, leaf 4, H34, H(H12, H34) are stored at index 4, 5, 6
+func nodeIndex(start uint64, height uint64) uint64 {
+	end := start + (1 << height)
+	return totalStoredHashNum(end) - 1 - (lowBit(end) - 1 - height)
+}
+
 // emptyHash return fix result:
 // e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855
 func emptyHash() common.Uint256 {
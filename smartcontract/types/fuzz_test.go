@@ -0,0 +1,16 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/common/serialize/serializetest"
+)
+
+func FuzzVMCode(f *testing.F) {
+	serializetest.AddSeeds(f,
+		&VMCode{VMType: NEOVM},
+		&VMCode{VMType: NEOVM, Code: []byte{0xFF, 0x00}},
+	)
+	serializetest.Fuzz(f, func() serialize.Serializable { return new(VMCode) })
+}
@@ -2,7 +2,7 @@ package types
 
 import (
 	"crypto/sha256"
-	"encoding/binary"
+	"encoding/json"
 	"io"
 
 	"github.com/mileschao/echain/common"
@@ -23,6 +23,34 @@ const (
 	// EVM = VmType(0x90)
 )
 
+var vmTypeNames = map[byte]string{
+	byte(Native): "Native",
+	byte(NEOVM):  "NEOVM",
+	byte(WASMVM): "WASMVM",
+}
+
+// String get the name of vm type
+func (vt VMType) String() string {
+	return common.ByteName(byte(vt), vmTypeNames)
+}
+
+// MarshalText implement encoding.TextMarshaler, vm type is encoded as name
+func (vt VMType) MarshalText() ([]byte, error) {
+	return []byte(vt.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler
+func (vt *VMType) UnmarshalText(text []byte) error {
+	v, err := common.ParseByteName(string(text), vmTypeNames)
+	*vt = VMType(v)
+	return err
+}
+
+// IsValid check whether vm type is one of Native, NEOVM and WASMVM
+func (vt VMType) IsValid() bool {
+	return vt == Native || vt == NEOVM || vt == WASMVM
+}
+
 // VMCode describe smart contract code and vm type
 type VMCode struct {
 	VMType VMType
@@ -31,23 +59,30 @@ type VMCode struct {
 
 // Serialize implement serilaizable interface
 func (vc *VMCode) Serialize(w io.Writer) error {
-	binary.Write(w, binary.LittleEndian, vc.VMType)
-	var vcvb = &serialize.VarBytes{
-		Len:   uint64(len(vc.Code)),
-		Bytes: vc.Code,
-	}
-	return vcvb.Serialize(w)
+	return serialize.WriteTo(w, vc)
 }
 
 // Deserialize implement serializable interface
 func (vc *VMCode) Deserialize(r io.Reader) error {
-	binary.Read(r, binary.LittleEndian, &vc.VMType)
-	var vcvb serialize.VarBytes
-	if err := vcvb.Deserialize(r); err != nil {
+	return serialize.ReadFrom(r, vc)
+}
+
+// SerializeSink implement SinkSerializable interface
+func (vc *VMCode) SerializeSink(sink *serialize.Sink) error {
+	sink.WriteUint8(byte(vc.VMType))
+	sink.WriteVarBytes(vc.Code)
+	return nil
+}
+
+// DeserializeSource implement SinkSerializable interface
+func (vc *VMCode) DeserializeSource(source *serialize.Source) error {
+	vmType, err := source.ReadUint8()
+	if err != nil {
 		return err
 	}
-	vc.Code = vcvb.Bytes
-	return nil
+	vc.VMType = VMType(vmType)
+	vc.Code, err = source.ReadVarBytes(serialize.MaxVarBytesLen)
+	return err
 }
 
 // Address return address of contract
@@ -64,10 +99,26 @@ func (vc *VMCode) Address() common.Address {
 
 // IsVMCodeAddress check whether address is smart contract address
 func IsVMCodeAddress(addr common.Address) bool {
-	vmType := addr[0]
-	if vmType == byte(Native) || vmType == byte(NEOVM) || vmType == byte(WASMVM) {
-		return true
-	}
+	return VMType(addr[0]).IsValid()
+}
 
-	return false
+type vmCodeJSON struct {
+	VMType VMType          `json:"vm_type"`
+	Code   common.HexBytes `json:"code"`
+}
+
+// MarshalJSON implement json.Marshaler
+func (vc VMCode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&vmCodeJSON{VMType: vc.VMType, Code: vc.Code})
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+func (vc *VMCode) UnmarshalJSON(data []byte) error {
+	var vj vmCodeJSON
+	if err := json.Unmarshal(data, &vj); err != nil {
+		return err
+	}
+	vc.VMType = vj.VMType
+	vc.Code = vj.Code
+	return nil
 }
@@ -99,6 +99,9 @@ func (s *Storage) Put(key []byte, value []byte) error {
 //Get implement Persist storage interface
 func (s *Storage) Get(key []byte) ([]byte, error) {
 	dat, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, storage.ErrNotFound
+	}
 	return dat, err
 }
 
@@ -128,13 +131,11 @@ func (s *Storage) BatchDelete(key []byte) {
 }
 
 //BatchCommit implement Persist storage interface
+//the batch is dropped even if it fails to commit
 func (s *Storage) BatchCommit() error {
-	err := s.db.Write(s.batch, nil)
-	if err != nil {
-		return err
-	}
+	batch := s.batch
 	s.batch = nil
-	return nil
+	return s.db.Write(batch, nil)
 }
 
 //Close implement Persist storage interface
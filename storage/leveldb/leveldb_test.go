@@ -0,0 +1,27 @@
+package leveldb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.TestPersistStorage(t, func(t *testing.T) (storage.PersistStorage, func()) {
+		dir, err := ioutil.TempDir("", "leveldb")
+		if err != nil {
+			t.Fatalf("temp dir: %s", err)
+		}
+		store, err := NewStore(dir)
+		if err != nil {
+			t.Fatalf("new leveldb store: %s", err)
+		}
+		return store, func() {
+			store.Close()
+			os.RemoveAll(dir)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package memdb
+
+import (
+	"sync"
+
+	"github.com/mileschao/echain/storage"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	ldbmemdb "github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// default capacity of memory database, it grows if necessary
+const defaultCapacity = 4 * 1024 * 1024
+
+//Storage in-memory storage ordered by key
+// implement PersistStorage interface, for tests and ephemeral nodes
+type Storage struct {
+	sync.RWMutex // batch commit is atomic against Get and Has
+	db           *ldbmemdb.DB
+	batch        *leveldb.Batch
+}
+
+//NewStore return an new empty in-memory storage
+func NewStore() *Storage {
+	return &Storage{
+		db: ldbmemdb.New(comparer.DefaultComparer, defaultCapacity),
+	}
+}
+
+//Put implement Persist storage interface
+func (s *Storage) Put(key []byte, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	return s.db.Put(key, value)
+}
+
+//Get implement Persist storage interface
+func (s *Storage) Get(key []byte) ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+	dat, err := s.db.Get(key)
+	if err == ldbmemdb.ErrNotFound {
+		return nil, storage.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	// the value returned by memdb refers to its internal buffer
+	return append([]byte{}, dat...), nil
+}
+
+//Has implement Persist storage interface
+func (s *Storage) Has(key []byte) (bool, error) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.db.Contains(key), nil
+}
+
+//Delete implement Persist storage interface
+func (s *Storage) Delete(key []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	return s.delete(key)
+}
+
+// delete the key, which is not an error if not exists
+func (s *Storage) delete(key []byte) error {
+	if err := s.db.Delete(key); err != nil && err != ldbmemdb.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+//NewBatch implement Persist storage interface
+func (s *Storage) NewBatch() {
+	s.batch = new(leveldb.Batch)
+}
+
+//BatchPut implement Persist storage interface
+func (s *Storage) BatchPut(key []byte, value []byte) {
+	s.batch.Put(key, value)
+}
+
+//BatchDelete implement Persist storage interface
+func (s *Storage) BatchDelete(key []byte) {
+	s.batch.Delete(key)
+}
+
+//BatchCommit implement Persist storage interface
+//the batch is dropped even if it fails to commit, nothing is written without batch
+func (s *Storage) BatchCommit() error {
+	s.Lock()
+	defer s.Unlock()
+	batch := s.batch
+	s.batch = nil
+	if batch == nil {
+		return nil
+	}
+	replay := &batchReplay{s: s}
+	if err := batch.Replay(replay); err != nil {
+		return err
+	}
+	return replay.err
+}
+
+//Close implement Persist storage interface
+func (s *Storage) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.db.Reset()
+	return nil
+}
+
+//NewIterator implement Persist storage interface
+// the items of prefix are copied into a snapshot under the read lock,
+// thus the batch committed during iteration is not visible
+func (s *Storage) NewIterator(prefix []byte) storage.Iterator {
+	s.RLock()
+	defer s.RUnlock()
+	snapshot := ldbmemdb.New(comparer.DefaultComparer, 0)
+	iter := s.db.NewIterator(util.BytesPrefix(prefix))
+	defer iter.Release()
+	for iter.Next() {
+		snapshot.Put(iter.Key(), iter.Value())
+	}
+	return snapshot.NewIterator(nil)
+}
+
+// batchReplay apply the operations of batch into memdb in order
+type batchReplay struct {
+	s   *Storage
+	err error
+}
+
+func (br *batchReplay) Put(key, value []byte) {
+	if br.err == nil {
+		br.err = br.s.db.Put(key, value)
+	}
+}
+
+func (br *batchReplay) Delete(key []byte) {
+	if br.err == nil {
+		br.err = br.s.delete(key)
+	}
+}
@@ -0,0 +1,15 @@
+package memdb
+
+import (
+	"testing"
+
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.TestPersistStorage(t, func(t *testing.T) (storage.PersistStorage, func()) {
+		store := NewStore()
+		return store, func() { store.Close() }
+	})
+}
@@ -0,0 +1,96 @@
+package memstore
+
+import (
+	"github.com/mileschao/echain/storage"
+)
+
+// CacheStore stage the state changes in memory over PersistStorage,
+// the items not in memory are loaded from PersistStorage as RawValue.
+// the changes are written into PersistStorage by Commit, or thrown away by Discard
+type CacheStore struct {
+	*MemStore
+	store storage.PersistStorage
+}
+
+// NewCacheStore create an new cache store over the persist storage
+func NewCacheStore(store storage.PersistStorage) *CacheStore {
+	return &CacheStore{
+		MemStore: NewMemStore(),
+		store:    store,
+	}
+}
+
+// TryGet get the item from memory, or load it from PersistStorage if not cached
+// nil returned if the key is not found or marked Deleted. A copy of the item is
+// returned, the changes should be written back by Put
+func (cs *CacheStore) TryGet(prefix byte, key []byte) (*storage.StateItem, error) {
+	k := itemKey(prefix, key)
+	if item, ok := cs.items[k]; ok {
+		if item.State == storage.Deleted {
+			return nil, nil
+		}
+		return copyItem(item), nil
+	}
+	data, err := cs.store.Get([]byte(k))
+	if err == storage.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	item := &storage.StateItem{
+		Key:   k,
+		Value: &RawValue{Bytes: data},
+		State: storage.None,
+	}
+	cs.items[k] = item
+	return copyItem(item), nil
+}
+
+// Get implement storage.MemoryStorage interface, the error of PersistStorage is regarded as not found
+func (cs *CacheStore) Get(prefix byte, key []byte) *storage.StateItem {
+	item, err := cs.TryGet(prefix, key)
+	if err != nil {
+		return nil
+	}
+	return item
+}
+
+// Find implement storage.MemoryStorage interface, the items of PersistStorage
+// are merged with the cached items in order of key
+func (cs *CacheStore) Find() []*storage.StateItem {
+	return cs.find("")
+}
+
+// FindPrefix the same as Find, but only the items whose key starts with prefix + key
+// are read from PersistStorage
+func (cs *CacheStore) FindPrefix(prefix byte, key []byte) []*storage.StateItem {
+	return cs.find(itemKey(prefix, key))
+}
+
+func (cs *CacheStore) find(start string) []*storage.StateItem {
+	var persisted []*storage.StateItem
+	iter := cs.store.NewIterator([]byte(start))
+	defer iter.Release()
+	for iter.Next() {
+		persisted = append(persisted, &storage.StateItem{
+			Key:   string(iter.Key()),
+			Value: &RawValue{Bytes: append([]byte(nil), iter.Value()...)},
+			State: storage.None,
+		})
+	}
+	return mergeItems(cs.items, persisted, start)
+}
+
+// Commit flush the changes into PersistStorage atomically, then clear the cache
+func (cs *CacheStore) Commit() error {
+	if err := Commit(cs.MemStore, cs.store); err != nil {
+		return err
+	}
+	cs.Reset()
+	return nil
+}
+
+// Discard throw away the changes
+func (cs *CacheStore) Discard() {
+	cs.Reset()
+}
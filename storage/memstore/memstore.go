@@ -0,0 +1,218 @@
+package memstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mileschao/echain/common/serialize"
+	"github.com/mileschao/echain/storage"
+)
+
+var (
+	// ErrNilValue item marked Changed without value
+	ErrNilValue = errors.New("changed item without value")
+)
+
+// RawValue value loaded from PersistStorage which is not decoded
+// the consumer should decode it by the type of state, i.e.
+//
+//	state.Deserialize(bytes.NewReader(item.Value.(*RawValue).Bytes))
+type RawValue struct {
+	Bytes []byte
+}
+
+// Serialize implement Serializable interface
+func (rv *RawValue) Serialize(w io.Writer) error {
+	_, err := w.Write(rv.Bytes)
+	return err
+}
+
+// Deserialize implement Serializable interface, all the remains of r are read
+func (rv *RawValue) Deserialize(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	rv.Bytes = data
+	return nil
+}
+
+// MemStore implement storage.MemoryStorage with map keyed by prefix + key,
+// which is the same as the key in PersistStorage
+type MemStore struct {
+	items map[string]*storage.StateItem
+}
+
+// NewMemStore create an new empty memory store
+func NewMemStore() *MemStore {
+	return &MemStore{
+		items: make(map[string]*storage.StateItem),
+	}
+}
+
+// Put implement storage.MemoryStorage interface
+func (ms *MemStore) Put(prefix byte, key []byte, value serialize.Serializable, state storage.ItemState) {
+	k := itemKey(prefix, key)
+	ms.items[k] = &storage.StateItem{
+		Key:   k,
+		Value: value,
+		State: state,
+	}
+}
+
+// Get implement storage.MemoryStorage interface
+// nil returned if the key is not in store or marked Deleted. A copy of the item is
+// returned, the changes should be written back by Put
+func (ms *MemStore) Get(prefix byte, key []byte) *storage.StateItem {
+	item := ms.items[itemKey(prefix, key)]
+	if item == nil || item.State == storage.Deleted {
+		return nil
+	}
+	return copyItem(item)
+}
+
+// Delete implement storage.MemoryStorage interface
+// the key is marked Deleted, thus it is deleted from PersistStorage when committed
+func (ms *MemStore) Delete(prefix byte, key []byte) {
+	k := itemKey(prefix, key)
+	if item, ok := ms.items[k]; ok {
+		item.State = storage.Deleted
+		return
+	}
+	ms.items[k] = &storage.StateItem{
+		Key:   k,
+		State: storage.Deleted,
+	}
+}
+
+// GetChangeSet implement storage.MemoryStorage interface
+func (ms *MemStore) GetChangeSet() map[string]*storage.StateItem {
+	changes := make(map[string]*storage.StateItem)
+	for k, item := range ms.items {
+		if item.State != storage.None {
+			changes[k] = item
+		}
+	}
+	return changes
+}
+
+// Find implement storage.MemoryStorage interface
+// the copies of items not marked Deleted are returned in order of key
+func (ms *MemStore) Find() []*storage.StateItem {
+	return ms.find("")
+}
+
+// FindPrefix the same as Find, but only the items whose key starts with prefix + key
+func (ms *MemStore) FindPrefix(prefix byte, key []byte) []*storage.StateItem {
+	return ms.find(itemKey(prefix, key))
+}
+
+func (ms *MemStore) find(start string) []*storage.StateItem {
+	items := make([]*storage.StateItem, 0, len(ms.items))
+	for _, item := range ms.items {
+		if item.State != storage.Deleted && strings.HasPrefix(item.Key, start) {
+			items = append(items, copyItem(item))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// copyItem copy the item with a cloned value, thus the in-place mutation of
+// the copy does not leak into the layer it is read from
+func copyItem(item *storage.StateItem) *storage.StateItem {
+	c := item.Copy()
+	c.Value = cloneValue(item.Value)
+	return c
+}
+
+// cloneValue deep copy the value by serialization. RawValue and nil are
+// copied directly, the value is shared if it can not be cloned
+func cloneValue(v serialize.Serializable) serialize.Serializable {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case *RawValue:
+		return &RawValue{Bytes: append([]byte(nil), val.Bytes...)}
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr {
+		return v
+	}
+	buf := new(bytes.Buffer)
+	if err := v.Serialize(buf); err != nil {
+		return v
+	}
+	c, ok := reflect.New(t.Elem()).Interface().(serialize.Serializable)
+	if !ok || c.Deserialize(buf) != nil {
+		return v
+	}
+	return c
+}
+
+// mergeItems merge the items of layer whose key starts with start over the items
+// of parent in order of key, the items marked Deleted in layer are removed
+func mergeItems(layer map[string]*storage.StateItem, parent []*storage.StateItem, start string) []*storage.StateItem {
+	items := make([]*storage.StateItem, 0, len(layer)+len(parent))
+	for _, item := range parent {
+		if _, ok := layer[item.Key]; !ok {
+			items = append(items, item)
+		}
+	}
+	for _, item := range layer {
+		if item.State != storage.Deleted && strings.HasPrefix(item.Key, start) {
+			items = append(items, copyItem(item))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// Reset discard all the items
+func (ms *MemStore) Reset() {
+	ms.items = make(map[string]*storage.StateItem)
+}
+
+// Commit flush the change set of memory store into PersistStorage atomically
+// the values are serialized before the batch is created, thus no batch is left on error.
+// ErrNilValue returned if any item is marked Changed without value
+func Commit(mem storage.MemoryStorage, store storage.PersistStorage) error {
+	changes := mem.GetChangeSet()
+	values := make(map[string][]byte, len(changes))
+	for k, item := range changes {
+		if item.State != storage.Changed {
+			continue
+		}
+		if item.Value == nil {
+			return ErrNilValue
+		}
+		buf := new(bytes.Buffer)
+		if err := item.Value.Serialize(buf); err != nil {
+			return err
+		}
+		values[k] = buf.Bytes()
+	}
+	store.NewBatch()
+	for k, item := range changes {
+		switch item.State {
+		case storage.Changed:
+			store.BatchPut([]byte(k), values[k])
+		case storage.Deleted:
+			store.BatchDelete([]byte(k))
+		}
+	}
+	return store.BatchCommit()
+}
+
+func itemKey(prefix byte, key []byte) string {
+	return string(append([]byte{prefix}, key...))
+}
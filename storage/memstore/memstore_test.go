@@ -0,0 +1,151 @@
+package memstore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mileschao/echain/storage"
+	"github.com/mileschao/echain/storage/memdb"
+)
+
+func newTestStore(t *testing.T) (storage.PersistStorage, func()) {
+	store := memdb.NewStore()
+	return store, func() {
+		store.Close()
+	}
+}
+
+func TestMemStore(t *testing.T) {
+	ms := NewMemStore()
+	ms.Put(1, []byte("a"), &RawValue{Bytes: []byte("1")}, storage.Changed)
+	ms.Put(1, []byte("b"), &RawValue{Bytes: []byte("2")}, storage.None)
+	ms.Put(2, []byte("a"), &RawValue{Bytes: []byte("3")}, storage.Changed)
+	ms.Delete(1, []byte("c"))
+
+	if item := ms.Get(1, []byte("a")); item == nil || !bytes.Equal(item.Value.(*RawValue).Bytes, []byte("1")) {
+		t.Errorf("get item: %v", item)
+	}
+	if item := ms.Get(1, []byte("c")); item != nil {
+		t.Errorf("get deleted item: %v", item)
+	}
+	item := ms.Get(1, []byte("a"))
+	item.Value.(*RawValue).Bytes[0] = 'x'
+	item.State = storage.None
+	if item := ms.Get(1, []byte("a")); !bytes.Equal(item.Value.(*RawValue).Bytes, []byte("1")) || item.State != storage.Changed {
+		t.Errorf("get item after mutating the copy: %v", item)
+	}
+	if changes := ms.GetChangeSet(); len(changes) != 3 || changes["\x01c"].State != storage.Deleted {
+		t.Errorf("change set: %d", len(changes))
+	}
+	ms.Delete(1, []byte("a"))
+	items := ms.Find()
+	if len(items) != 2 || items[0].Key != "\x01b" || items[1].Key != "\x02a" {
+		t.Errorf("find: %d", len(items))
+	}
+}
+
+func TestCommitNilValue(t *testing.T) {
+	store, closer := newTestStore(t)
+	defer closer()
+
+	ms := NewMemStore()
+	ms.Put(1, []byte("a"), &RawValue{Bytes: []byte("1")}, storage.Changed)
+	ms.Put(1, []byte("b"), nil, storage.Changed)
+	if err := Commit(ms, store); err != ErrNilValue {
+		t.Errorf("commit nil value: %v", err)
+	}
+	if _, err := store.Get([]byte("\x01a")); err != storage.ErrNotFound {
+		t.Errorf("item committed with nil value: %v", err)
+	}
+}
+
+func TestCacheStoreCommit(t *testing.T) {
+	store, closer := newTestStore(t)
+	defer closer()
+	store.Put([]byte("\x01a"), []byte("old"))
+	store.Put([]byte("\x01b"), []byte("removed"))
+
+	cs := NewCacheStore(store)
+	item, err := cs.TryGet(1, []byte("a"))
+	if err != nil || item == nil || item.State != storage.None || !bytes.Equal(item.Value.(*RawValue).Bytes, []byte("old")) {
+		t.Fatalf("load item: %v", err)
+	}
+	if item := cs.Get(1, []byte("x")); item != nil {
+		t.Errorf("get not found item: %v", item)
+	}
+	cs.Put(1, []byte("a"), &RawValue{Bytes: []byte("new")}, storage.Changed)
+	cs.Put(1, []byte("c"), &RawValue{Bytes: []byte("added")}, storage.Changed)
+	cs.Delete(1, []byte("b"))
+	if item := cs.Get(1, []byte("b")); item != nil {
+		t.Errorf("get deleted item: %v", item)
+	}
+	if v, _ := store.Get([]byte("\x01a")); !bytes.Equal(v, []byte("old")) {
+		t.Errorf("changes are written before commit")
+	}
+
+	if err := cs.Commit(); err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+	if v, err := store.Get([]byte("\x01a")); err != nil || !bytes.Equal(v, []byte("new")) {
+		t.Errorf("committed item: %v", err)
+	}
+	if v, err := store.Get([]byte("\x01c")); err != nil || !bytes.Equal(v, []byte("added")) {
+		t.Errorf("committed new item: %v", err)
+	}
+	if _, err := store.Get([]byte("\x01b")); err != storage.ErrNotFound {
+		t.Errorf("committed deleted item: %v", err)
+	}
+	if len(cs.GetChangeSet()) != 0 {
+		t.Errorf("change set after commit")
+	}
+}
+
+// iterStore record the prefixes of iterators
+type iterStore struct {
+	storage.PersistStorage
+	prefixes []string
+}
+
+func (s *iterStore) NewIterator(prefix []byte) storage.Iterator {
+	s.prefixes = append(s.prefixes, string(prefix))
+	return s.PersistStorage.NewIterator(prefix)
+}
+
+func TestCacheStoreFindPrefix(t *testing.T) {
+	store := &iterStore{PersistStorage: memdb.NewStore()}
+	defer store.Close()
+	store.Put([]byte("\x01a1"), []byte("1"))
+	store.Put([]byte("\x01a2"), []byte("2"))
+	store.Put([]byte("\x01b"), []byte("3"))
+
+	cs := NewCacheStore(store)
+	cs.Put(1, []byte("a3"), &RawValue{Bytes: []byte("4")}, storage.Changed)
+	cs.Put(1, []byte("c"), &RawValue{Bytes: []byte("5")}, storage.Changed)
+	cs.Delete(1, []byte("a1"))
+	items := cs.FindPrefix(1, []byte("a"))
+	if len(items) != 2 || items[0].Key != "\x01a2" || items[1].Key != "\x01a3" {
+		t.Errorf("find prefix: %d", len(items))
+	}
+	if len(store.prefixes) != 1 || store.prefixes[0] != "\x01a" {
+		t.Errorf("iterator prefixes: %q", store.prefixes)
+	}
+}
+
+func TestCacheStoreDiscard(t *testing.T) {
+	store, closer := newTestStore(t)
+	defer closer()
+	store.Put([]byte("\x01a"), []byte("old"))
+
+	cs := NewCacheStore(store)
+	cs.Put(1, []byte("a"), &RawValue{Bytes: []byte("new")}, storage.Changed)
+	cs.Discard()
+	if err := cs.Commit(); err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+	if v, err := store.Get([]byte("\x01a")); err != nil || !bytes.Equal(v, []byte("old")) {
+		t.Errorf("discarded item: %v", err)
+	}
+	if item := cs.Get(1, []byte("a")); item == nil || !bytes.Equal(item.Value.(*RawValue).Bytes, []byte("old")) {
+		t.Errorf("get item after discard: %v", item)
+	}
+}
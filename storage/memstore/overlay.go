@@ -0,0 +1,87 @@
+package memstore
+
+import (
+	"github.com/mileschao/echain/storage"
+)
+
+// Layer state layer that overlays can be stacked on, i.e. CacheStore and Overlay
+type Layer interface {
+	storage.MemoryStorage
+	TryGet(prefix byte, key []byte) (*storage.StateItem, error)
+	FindPrefix(prefix byte, key []byte) []*storage.StateItem
+}
+
+// Overlay stage the state changes over the parent layer,
+// the reads fall through to parent if the key is not changed in the overlay.
+// the changes are written into parent by Commit, or thrown away by Discard
+// without touching parent, i.e. revert the writes of a failed transaction:
+//
+//	block := NewCacheStore(store)
+//	tx := NewOverlay(block)
+//	... execute transaction on tx
+//	if failed { tx.Discard() } else { tx.Commit() }
+//	... block.Commit() at the end of block
+type Overlay struct {
+	*MemStore
+	parent Layer
+}
+
+// NewOverlay create an new overlay on parent layer
+func NewOverlay(parent Layer) *Overlay {
+	return &Overlay{
+		MemStore: NewMemStore(),
+		parent:   parent,
+	}
+}
+
+// TryGet get the item from overlay, or from parent if not changed in overlay
+// nil returned if the key is not found or marked Deleted. A copy of the item is
+// returned, the changes should be written back by Put
+func (ol *Overlay) TryGet(prefix byte, key []byte) (*storage.StateItem, error) {
+	if item, ok := ol.items[itemKey(prefix, key)]; ok {
+		if item.State == storage.Deleted {
+			return nil, nil
+		}
+		return copyItem(item), nil
+	}
+	return ol.parent.TryGet(prefix, key)
+}
+
+// Get implement storage.MemoryStorage interface, the error of parent is regarded as not found
+func (ol *Overlay) Get(prefix byte, key []byte) *storage.StateItem {
+	item, err := ol.TryGet(prefix, key)
+	if err != nil {
+		return nil
+	}
+	return item
+}
+
+// Find implement storage.MemoryStorage interface, the items of parent
+// are merged with the items of overlay in order of key
+func (ol *Overlay) Find() []*storage.StateItem {
+	return mergeItems(ol.items, ol.parent.Find(), "")
+}
+
+// FindPrefix the same as Find, but only the items whose key starts with prefix + key
+func (ol *Overlay) FindPrefix(prefix byte, key []byte) []*storage.StateItem {
+	return mergeItems(ol.items, ol.parent.FindPrefix(prefix, key), itemKey(prefix, key))
+}
+
+// Commit write the changes into parent, then clear the overlay
+func (ol *Overlay) Commit() {
+	for k, item := range ol.GetChangeSet() {
+		prefix, key := k[0], []byte(k[1:])
+		switch item.State {
+		case storage.Changed:
+			ol.parent.Put(prefix, key, item.Value, storage.Changed)
+		case storage.Deleted:
+			ol.parent.Delete(prefix, key)
+		}
+	}
+	ol.Reset()
+}
+
+// Discard throw away the changes without touching parent
+func (ol *Overlay) Discard() {
+	ol.Reset()
+}
@@ -0,0 +1,139 @@
+package memstore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mileschao/echain/storage"
+)
+
+func itemBytes(item *storage.StateItem) []byte {
+	if item == nil {
+		return nil
+	}
+	return item.Value.(*RawValue).Bytes
+}
+
+func TestOverlay(t *testing.T) {
+	store, closer := newTestStore(t)
+	defer closer()
+	store.Put([]byte("\x01a"), []byte("persisted"))
+	store.Put([]byte("\x01b"), []byte("persisted"))
+	store.Put([]byte("\x02a"), []byte("persisted"))
+
+	block := NewCacheStore(store)
+	block.Put(1, []byte("c"), &RawValue{Bytes: []byte("block")}, storage.Changed)
+	block.Put(2, []byte("b"), &RawValue{Bytes: []byte("block")}, storage.Changed)
+
+	// failed transaction
+	tx := NewOverlay(block)
+	tx.Put(1, []byte("a"), &RawValue{Bytes: []byte("failed")}, storage.Changed)
+	tx.Delete(1, []byte("c"))
+	if v := itemBytes(tx.Get(1, []byte("a"))); !bytes.Equal(v, []byte("failed")) {
+		t.Errorf("get item of overlay: %s", v)
+	}
+	if item := tx.Get(1, []byte("c")); item != nil {
+		t.Errorf("get deleted item of overlay: %v", item)
+	}
+	tx.Discard()
+	if v := itemBytes(tx.Get(1, []byte("a"))); !bytes.Equal(v, []byte("persisted")) {
+		t.Errorf("get item after discard: %s", v)
+	}
+	if v := itemBytes(block.Get(1, []byte("c"))); !bytes.Equal(v, []byte("block")) {
+		t.Errorf("get block item after discard: %s", v)
+	}
+
+	// succeeded transaction with a failed nested invocation
+	tx = NewOverlay(block)
+	tx.Put(1, []byte("a"), &RawValue{Bytes: []byte("tx")}, storage.Changed)
+	tx.Delete(1, []byte("b"))
+	call := NewOverlay(tx)
+	call.Put(1, []byte("a"), &RawValue{Bytes: []byte("call")}, storage.Changed)
+	if v := itemBytes(call.Get(1, []byte("c"))); !bytes.Equal(v, []byte("block")) {
+		t.Errorf("get block item from nested overlay: %s", v)
+	}
+	call.Discard()
+	tx.Commit()
+	if v := itemBytes(block.Get(1, []byte("a"))); !bytes.Equal(v, []byte("tx")) {
+		t.Errorf("get committed item of block: %s", v)
+	}
+	if item := block.Get(1, []byte("b")); item != nil {
+		t.Errorf("get committed deleted item of block: %v", item)
+	}
+	if v, _ := store.Get([]byte("\x01a")); !bytes.Equal(v, []byte("persisted")) {
+		t.Errorf("transaction is written before block commit: %s", v)
+	}
+
+	if err := block.Commit(); err != nil {
+		t.Fatalf("commit block: %s", err)
+	}
+	if v, _ := store.Get([]byte("\x01a")); !bytes.Equal(v, []byte("tx")) {
+		t.Errorf("committed item: %s", v)
+	}
+	if _, err := store.Get([]byte("\x01b")); err != storage.ErrNotFound {
+		t.Errorf("committed deleted item: %v", err)
+	}
+	if v, _ := store.Get([]byte("\x01c")); !bytes.Equal(v, []byte("block")) {
+		t.Errorf("committed block item: %s", v)
+	}
+}
+
+func TestOverlayMutateDiscard(t *testing.T) {
+	store, closer := newTestStore(t)
+	defer closer()
+	store.Put([]byte("\x01a"), []byte("persisted"))
+
+	block := NewCacheStore(store)
+	block.Put(1, []byte("b"), &RawValue{Bytes: []byte("block")}, storage.Changed)
+
+	tx := NewOverlay(block)
+	for _, key := range []string{"a", "b"} {
+		item := tx.Get(1, []byte(key))
+		item.Value.(*RawValue).Bytes[0] = 'X'
+		item.State = storage.Deleted
+	}
+	tx.Discard()
+	if v := itemBytes(block.Get(1, []byte("a"))); !bytes.Equal(v, []byte("persisted")) {
+		t.Errorf("loaded item of parent is mutated: %s", v)
+	}
+	if v := itemBytes(block.Get(1, []byte("b"))); !bytes.Equal(v, []byte("block")) {
+		t.Errorf("changed item of parent is mutated: %s", v)
+	}
+}
+
+func TestOverlayFind(t *testing.T) {
+	store, closer := newTestStore(t)
+	defer closer()
+	store.Put([]byte("\x01a"), []byte("persisted"))
+	store.Put([]byte("\x01b"), []byte("persisted"))
+	store.Put([]byte("\x02a"), []byte("persisted"))
+
+	block := NewCacheStore(store)
+	block.Put(1, []byte("c"), &RawValue{Bytes: []byte("block")}, storage.Changed)
+	block.Put(2, []byte("b"), &RawValue{Bytes: []byte("block")}, storage.Changed)
+	block.Delete(1, []byte("b"))
+	tx := NewOverlay(block)
+	tx.Put(1, []byte("a"), &RawValue{Bytes: []byte("tx")}, storage.Changed)
+	tx.Put(1, []byte("d"), &RawValue{Bytes: []byte("tx")}, storage.Changed)
+
+	expect := []struct{ key, value string }{
+		{"\x01a", "tx"},
+		{"\x01c", "block"},
+		{"\x01d", "tx"},
+	}
+	if items := tx.Find(); len(items) != len(expect)+2 {
+		t.Errorf("find %d items, expect %d", len(items), len(expect)+2)
+	}
+	if items := tx.FindPrefix(1, []byte("c")); len(items) != 1 || items[0].Key != "\x01c" {
+		t.Errorf("find %d items of key prefix", len(items))
+	}
+	items := tx.FindPrefix(1, nil)
+	if len(items) != len(expect) {
+		t.Fatalf("find %d items, expect %d", len(items), len(expect))
+	}
+	for i, e := range expect {
+		if items[i].Key != e.key || !bytes.Equal(itemBytes(items[i]), []byte(e.value)) {
+			t.Errorf("item %d: %q=%s, expect %q=%s", i, items[i].Key, itemBytes(items[i]), e.key, e.value)
+		}
+	}
+}
@@ -5,8 +5,8 @@ type DataEntryPrefix byte
 const (
 	// DATA
 	DATA_BLOCK       DataEntryPrefix = 0x00 //Block height => block hash key prefix
-	DATA_HEADER                      = 0x01 //Block hash => block hash key prefix
-	DATA_TRANSACTION                 = 0x02 //Transction hash = > transaction key prefix
+	DATA_HEADER      DataEntryPrefix = 0x01 //Block hash => block header key prefix
+	DATA_TRANSACTION DataEntryPrefix = 0x02 //Transction hash = > block height + transaction key prefix
 
 	// Transaction
 	ST_BOOKKEEPER DataEntryPrefix = 0x03 //BookKeeper state key prefix
@@ -24,4 +24,6 @@ const (
 	SYS_BLOCK_MERKLE_TREE  DataEntryPrefix = 0x13 // Block merkle tree root key prefix
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
+
+	DATA_BLOCK_TX_LIST DataEntryPrefix = 0x15 //Block hash => transaction hash list key prefix
 )
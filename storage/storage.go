@@ -1,9 +1,16 @@
 package storage
 
 import (
+	"errors"
+
 	"github.com/mileschao/echain/common/serialize"
 )
 
+var (
+	// ErrNotFound key not found in storage
+	ErrNotFound = errors.New("not found")
+)
+
 type ItemState byte
 
 //Status of item
@@ -29,13 +36,13 @@ func (e *StateItem) Copy() *StateItem {
 //PersistStorage persistent storage
 type PersistStorage interface {
 	Put(key []byte, value []byte) error //Put the key-value pair to store
-	Get(key []byte) ([]byte, error)     //Get the value if key in store
+	Get(key []byte) ([]byte, error)     //Get the value if key in store, ErrNotFound if not
 	Has(key []byte) (bool, error)       //Whether the key is exist in store
 	Delete(key []byte) error            //Delete the key in store
 	NewBatch()                          //Start commit batch
 	BatchPut(key []byte, value []byte)  //Put a key-value pair to batch
 	BatchDelete(key []byte)             //Delete the key in batch
-	BatchCommit() error                 //Commit batch to store
+	BatchCommit() error                 //Commit batch to store, the batch is dropped even if failed
 	Close() error                       //Close store
 	NewIterator(prefix []byte) Iterator //Return the iterator of store
 }
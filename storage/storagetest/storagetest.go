@@ -0,0 +1,221 @@
+// Package storagetest conformance tests that every storage.PersistStorage backend must pass
+package storagetest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mileschao/echain/storage"
+)
+
+// NewStoreFunc create an new empty store, and the function to release it
+type NewStoreFunc func(t *testing.T) (storage.PersistStorage, func())
+
+// TestPersistStorage run the conformance tests on the stores created by newStore
+func TestPersistStorage(t *testing.T, newStore NewStoreFunc) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store storage.PersistStorage)
+	}{
+		{"PutGet", testPutGet},
+		{"Delete", testDelete},
+		{"Batch", testBatch},
+		{"Iterator", testIterator},
+		{"IteratorSeek", testIteratorSeek},
+	}
+	for _, test := range tests {
+		fn := test.fn
+		t.Run(test.name, func(t *testing.T) {
+			store, closer := newStore(t)
+			defer closer()
+			fn(t, store)
+		})
+	}
+}
+
+func testPutGet(t *testing.T, store storage.PersistStorage) {
+	if _, err := store.Get([]byte("k")); err != storage.ErrNotFound {
+		t.Errorf("get not found: %v", err)
+	}
+	if ok, err := store.Has([]byte("k")); ok || err != nil {
+		t.Errorf("has not found: %v", err)
+	}
+	value := []byte("v1")
+	if err := store.Put([]byte("k"), value); err != nil {
+		t.Fatalf("put: %s", err)
+	}
+	value[0] = 'x'
+	v, err := store.Get([]byte("k"))
+	if err != nil || !bytes.Equal(v, []byte("v1")) {
+		t.Errorf("get: %s, %v", v, err)
+	}
+	v[0] = 'x'
+	if v, _ := store.Get([]byte("k")); !bytes.Equal(v, []byte("v1")) {
+		t.Errorf("get after modifying returned value: %s", v)
+	}
+	if ok, err := store.Has([]byte("k")); !ok || err != nil {
+		t.Errorf("has: %v", err)
+	}
+	if err := store.Put([]byte("k"), []byte("v2")); err != nil {
+		t.Fatalf("put: %s", err)
+	}
+	if v, err := store.Get([]byte("k")); err != nil || !bytes.Equal(v, []byte("v2")) {
+		t.Errorf("get overwritten: %s, %v", v, err)
+	}
+	if err := store.Put([]byte("empty"), []byte{}); err != nil {
+		t.Fatalf("put empty value: %s", err)
+	}
+	if v, err := store.Get([]byte("empty")); err != nil || len(v) != 0 {
+		t.Errorf("get empty value: %s, %v", v, err)
+	}
+}
+
+func testDelete(t *testing.T, store storage.PersistStorage) {
+	if err := store.Put([]byte("k"), []byte("v")); err != nil {
+		t.Fatalf("put: %s", err)
+	}
+	if err := store.Delete([]byte("k")); err != nil {
+		t.Errorf("delete: %s", err)
+	}
+	if _, err := store.Get([]byte("k")); err != storage.ErrNotFound {
+		t.Errorf("get deleted: %v", err)
+	}
+	if err := store.Delete([]byte("k")); err != nil {
+		t.Errorf("delete not found: %s", err)
+	}
+}
+
+func testBatch(t *testing.T, store storage.PersistStorage) {
+	if err := store.Put([]byte("a"), []byte("1")); err != nil {
+		t.Fatalf("put: %s", err)
+	}
+	store.NewBatch()
+	store.BatchPut([]byte("b"), []byte("2"))
+	store.BatchPut([]byte("c"), []byte("3"))
+	store.BatchDelete([]byte("a"))
+	store.BatchDelete([]byte("c"))
+	store.BatchPut([]byte("b"), []byte("4"))
+	if _, err := store.Get([]byte("b")); err != storage.ErrNotFound {
+		t.Errorf("get before batch commit: %v", err)
+	}
+	if v, err := store.Get([]byte("a")); err != nil || !bytes.Equal(v, []byte("1")) {
+		t.Errorf("get deleted before batch commit: %v", err)
+	}
+	if err := store.BatchCommit(); err != nil {
+		t.Fatalf("batch commit: %s", err)
+	}
+	if _, err := store.Get([]byte("a")); err != storage.ErrNotFound {
+		t.Errorf("get deleted by batch: %v", err)
+	}
+	if v, err := store.Get([]byte("b")); err != nil || !bytes.Equal(v, []byte("4")) {
+		t.Errorf("get put by batch: %s, %v", v, err)
+	}
+	if _, err := store.Get([]byte("c")); err != storage.ErrNotFound {
+		t.Errorf("get put then deleted by batch: %v", err)
+	}
+	// the batch is dropped by commit
+	if err := store.BatchCommit(); err != nil {
+		t.Errorf("batch commit without batch: %s", err)
+	}
+	if v, err := store.Get([]byte("b")); err != nil || !bytes.Equal(v, []byte("4")) {
+		t.Errorf("get after batch commit without batch: %s, %v", v, err)
+	}
+}
+
+func putKeys(t *testing.T, store storage.PersistStorage, keys ...string) {
+	for _, k := range keys {
+		if err := store.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put: %s", err)
+		}
+	}
+}
+
+func testIterator(t *testing.T, store storage.PersistStorage) {
+	putKeys(t, store, "b2", "a", "b1", "b3", "c")
+
+	it := store.NewIterator([]byte("b"))
+	defer it.Release()
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		if !bytes.Equal(it.Value(), append([]byte("v"), it.Key()...)) {
+			t.Errorf("iterator value of %s: %s", it.Key(), it.Value())
+		}
+	}
+	if len(keys) != 3 || keys[0] != "b1" || keys[1] != "b2" || keys[2] != "b3" {
+		t.Errorf("iterate prefix: %v", keys)
+	}
+	if !it.Last() || string(it.Key()) != "b3" {
+		t.Errorf("iterator last: %s", it.Key())
+	}
+	if !it.Prev() || string(it.Key()) != "b2" {
+		t.Errorf("iterator prev: %s", it.Key())
+	}
+	if !it.First() || string(it.Key()) != "b1" {
+		t.Errorf("iterator first: %s", it.Key())
+	}
+	if it.Prev() {
+		t.Errorf("iterator prev of first: %s", it.Key())
+	}
+
+	all := store.NewIterator(nil)
+	defer all.Release()
+	n := 0
+	for all.Next() {
+		n++
+	}
+	if n != 5 {
+		t.Errorf("iterate all: %d", n)
+	}
+
+	empty := store.NewIterator([]byte("d"))
+	defer empty.Release()
+	if empty.Next() || empty.First() || empty.Last() {
+		t.Errorf("iterate empty prefix")
+	}
+
+	// the batch committed after creation is not visible to iterator
+	snapshot := store.NewIterator([]byte("b"))
+	defer snapshot.Release()
+	store.NewBatch()
+	store.BatchPut([]byte("b4"), []byte("vb4"))
+	store.BatchDelete([]byte("b1"))
+	if err := store.BatchCommit(); err != nil {
+		t.Fatalf("batch commit: %s", err)
+	}
+	keys = keys[:0]
+	for snapshot.Next() {
+		keys = append(keys, string(snapshot.Key()))
+	}
+	if len(keys) != 3 || keys[0] != "b1" || keys[2] != "b3" {
+		t.Errorf("iterate snapshot: %v", keys)
+	}
+}
+
+func testIteratorSeek(t *testing.T, store storage.PersistStorage) {
+	putKeys(t, store, "a", "b1", "b3", "b5", "c")
+
+	it := store.NewIterator([]byte("b"))
+	defer it.Release()
+	if !it.Seek([]byte("b3")) || string(it.Key()) != "b3" {
+		t.Errorf("seek existing key: %s", it.Key())
+	}
+	if !it.Seek([]byte("b2")) || string(it.Key()) != "b3" {
+		t.Errorf("seek between keys: %s", it.Key())
+	}
+	if !it.Prev() || string(it.Key()) != "b1" {
+		t.Errorf("prev after seek: %s", it.Key())
+	}
+	if !it.Seek([]byte("a")) || string(it.Key()) != "b1" {
+		t.Errorf("seek before prefix: %s", it.Key())
+	}
+	if it.Seek([]byte("b6")) {
+		t.Errorf("seek after last key: %s", it.Key())
+	}
+	if !it.Last() || string(it.Key()) != "b5" {
+		t.Errorf("last after seek: %s", it.Key())
+	}
+	if !it.Seek([]byte("b4")) || string(it.Key()) != "b5" || it.Next() {
+		t.Errorf("next after seek to last key: %s", it.Key())
+	}
+}